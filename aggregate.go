@@ -0,0 +1,176 @@
+package logpy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// AggregateHandler counts entries with the same fingerprint (by default level and
+// message) over an interval and emits one summary entry per fingerprint instead of
+// every entry, for hot code paths where even sampling is too verbose
+type AggregateHandler struct {
+	inner       Handler
+	interval    time.Duration
+	fingerprint FingerprintFunc
+	clock       Clock        // Timestamps summaries (nil = time.Now)
+	onError     ErrorHandler // Reports failures of summaries emitted in the background
+
+	mu      sync.Mutex
+	counts  map[string]*aggregateState
+	order   []string // Keys in first-seen order so summaries are stable
+	stop    chan struct{}
+	stopped sync.Once
+}
+
+// aggregateState tracks one message within the current interval
+type aggregateState struct {
+	entry Entry // First occurrence, used for the summary
+	count int
+}
+
+// NewAggregateHandler creates a handler that sends inner one summary per distinct
+// fingerprint every interval (default 1 minute)
+func NewAggregateHandler(inner Handler, interval time.Duration) *AggregateHandler {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	h := &AggregateHandler{
+		inner:       inner,
+		interval:    interval,
+		fingerprint: DefaultFingerprint,
+		counts:      make(map[string]*aggregateState),
+		stop:        make(chan struct{}),
+	}
+	go h.run()
+	return h
+}
+
+// run emits summaries at the end of every interval
+func (h *AggregateHandler) run() {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if summary, err := h.emit(); err != nil {
+				reportError(h.onError, err, summary)
+			}
+		case <-h.stop:
+			return
+		}
+	}
+}
+
+// SetFingerprint changes how entries are grouped (default DefaultFingerprint)
+// It should be called before logging
+func (h *AggregateHandler) SetFingerprint(fn FingerprintFunc) {
+	if fn == nil {
+		fn = DefaultFingerprint
+	}
+	h.fingerprint = fn
+}
+
+// SetClock sets the clock that timestamps summaries (nil means time.Now)
+// Use the logger's Config.Clock so summaries line up with other entries in tests
+func (h *AggregateHandler) SetClock(clock Clock) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.clock = clock
+}
+
+// SetErrorHandler reports failures of summaries emitted every interval to fn
+// They happen in the background, so the logger's error handler never sees them
+// nil prints them to stderr. Flush, Drain and Close return their failures instead
+func (h *AggregateHandler) SetErrorHandler(fn ErrorHandler) {
+	h.onError = fn
+}
+
+// Enabled implements the Handler interface
+func (h *AggregateHandler) Enabled(level Level) bool {
+	return h.inner.Enabled(level)
+}
+
+// Handle implements the Handler interface by counting the entry
+func (h *AggregateHandler) Handle(entry Entry) error {
+	key := h.fingerprint(entry)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if state, ok := h.counts[key]; ok {
+		state.count++
+		return nil
+	}
+	// Only the first occurrence is kept, so resolve its lazy fields now
+	h.counts[key] = &aggregateState{entry: entry.resolveLazy(), count: 1}
+	h.order = append(h.order, key)
+	return nil
+}
+
+// emit sends a summary for every fingerprint counted in the interval and starts a new one
+// It returns the failures along with the first summary that failed, for reporting
+func (h *AggregateHandler) emit() (Entry, error) {
+	h.mu.Lock()
+	counts, order := h.counts, h.order
+	h.counts = make(map[string]*aggregateState, len(counts))
+	h.order = nil
+	now := clockNow(h.clock)
+	h.mu.Unlock()
+
+	var failed Entry
+	var errs []error
+	for _, key := range order {
+		state := counts[key]
+		summary := state.entry
+		summary.Time = now
+		summary.Message = fmt.Sprintf("%s occurred %d times in last %s", state.entry.Message, state.count, h.interval)
+		// Event fields differ between occurrences; keep only the shared context
+		summary.Fields = []Field{Int("count", state.count)}
+		if err := h.inner.Handle(summary); err != nil {
+			if len(errs) == 0 {
+				failed = summary
+			}
+			errs = append(errs, err)
+		}
+	}
+	return failed, errors.Join(errs...)
+}
+
+// WithFields implements the Handler interface
+func (h *AggregateHandler) WithFields(fields []Field) Handler {
+	return h
+}
+
+// Flush emits summaries for the current interval and flushes the inner handler
+func (h *AggregateHandler) Flush() error {
+	_, err := h.emit()
+	if f, ok := h.inner.(Flusher); ok {
+		err = errors.Join(err, f.Flush())
+	}
+	return err
+}
+
+// Drain implements the Drainer interface for an asynchronous inner handler
+func (h *AggregateHandler) Drain(ctx context.Context) (int, error) {
+	h.stopped.Do(func() { close(h.stop) })
+	_, err := h.emit()
+	if d, ok := h.inner.(Drainer); ok {
+		n, drainErr := d.Drain(ctx)
+		return n, errors.Join(err, drainErr)
+	}
+	return 0, err
+}
+
+// Close emits the final summaries, stops the background goroutine and closes the inner handler
+func (h *AggregateHandler) Close() error {
+	h.stopped.Do(func() { close(h.stop) })
+	err := h.Flush()
+	if c, ok := h.inner.(io.Closer); ok {
+		err = errors.Join(err, c.Close())
+	}
+	return err
+}
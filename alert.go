@@ -0,0 +1,291 @@
+package logpy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Alert describes an alert raised or resolved by an AlertHandler
+type Alert struct {
+	Firing    bool          // true when raised, false when resolved
+	Key       string        // Fingerprint of the counted entries, empty when all are counted together
+	Count     int           // Matching entries in the window (capped at Threshold+1)
+	Threshold int           // Entries allowed per window before the alert fires
+	Window    time.Duration // Length of the sliding window
+	Entry     Entry         // Latest matching entry
+	Time      time.Time
+}
+
+// AlertFunc is called when an alert fires or resolves
+type AlertFunc func(alert Alert)
+
+// AlertHandler passes entries to an inner handler and raises an alert when more than
+// a threshold of entries at or above a level (Error by default) occur within a sliding
+// window. With hysteresis, the alert resolves only once the count falls to the resolve
+// threshold (half the threshold by default), so a rate hovering at the limit does not flap
+type AlertHandler struct {
+	inner       Handler
+	threshold   int
+	resolve     int
+	window      time.Duration
+	level       Level
+	fingerprint FingerprintFunc // nil counts all matching entries together
+	fn          AlertFunc
+	clock       Clock // Times counted entries and alerts (nil = time.Now)
+
+	mu      sync.Mutex
+	states  map[string]*alertState
+	stop    chan struct{}
+	stopped sync.Once
+}
+
+// alertState tracks matching entries for one fingerprint
+type alertState struct {
+	times  []time.Time // Newest last, at most threshold+1
+	last   Entry
+	firing bool
+}
+
+// NewAlertHandler creates a handler that calls fn when more than threshold Error
+// entries are passed to inner within window (default 5 minutes), and again when the
+// alert resolves. fn is called on the logging goroutine, so it should return quickly
+func NewAlertHandler(inner Handler, threshold int, window time.Duration, fn AlertFunc) *AlertHandler {
+	if threshold < 0 {
+		threshold = 0
+	}
+	if window <= 0 {
+		window = 5 * time.Minute
+	}
+
+	h := &AlertHandler{
+		inner:     inner,
+		threshold: threshold,
+		resolve:   threshold / 2,
+		window:    window,
+		level:     ErrorLevel,
+		fn:        fn,
+		states:    make(map[string]*alertState),
+		stop:      make(chan struct{}),
+	}
+	go h.run()
+	return h
+}
+
+// run resolves alerts whose rate has dropped while no new entries arrive
+func (h *AlertHandler) run() {
+	ticker := time.NewTicker(h.window / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			h.check()
+		case <-h.stop:
+			return
+		}
+	}
+}
+
+// SetLevel sets the minimum level of counted entries (default Error)
+// It should be called before logging
+func (h *AlertHandler) SetLevel(level Level) {
+	h.level = level
+}
+
+// SetResolveThreshold sets the count at or below which a firing alert resolves
+// It should be called before logging
+func (h *AlertHandler) SetResolveThreshold(n int) {
+	if n < 0 || n > h.threshold {
+		n = h.threshold / 2
+	}
+	h.resolve = n
+}
+
+// SetFingerprint counts entries with different fingerprints separately, so each
+// kind of error raises its own alert. nil counts all entries together (the default)
+// It should be called before logging
+func (h *AlertHandler) SetFingerprint(fn FingerprintFunc) {
+	h.fingerprint = fn
+}
+
+// SetClock sets the clock that times counted entries and alerts (nil means time.Now)
+// Use the logger's Config.Clock so windows follow a test clock
+func (h *AlertHandler) SetClock(clock Clock) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.clock = clock
+}
+
+// Firing reports whether any alert is currently firing
+func (h *AlertHandler) Firing() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, state := range h.states {
+		if state.firing {
+			return true
+		}
+	}
+	return false
+}
+
+// Enabled implements the Handler interface
+func (h *AlertHandler) Enabled(level Level) bool {
+	return h.inner.Enabled(level)
+}
+
+// Handle implements the Handler interface
+func (h *AlertHandler) Handle(entry Entry) error {
+	entry = entry.resolveLazy()
+	err := h.inner.Handle(entry)
+	if entry.Level.AtLeast(h.level) {
+		h.record(entry)
+	}
+	return err
+}
+
+// record counts a matching entry and fires an alert when the threshold is exceeded
+func (h *AlertHandler) record(entry Entry) {
+	key := ""
+	if h.fingerprint != nil {
+		key = h.fingerprint(entry)
+	}
+
+	h.mu.Lock()
+	now := clockNow(h.clock)
+	state, ok := h.states[key]
+	if !ok {
+		state = &alertState{}
+		h.states[key] = state
+	}
+	state.last = entry
+	state.times = append(state.times, now)
+	// Only whether the count exceeds the threshold matters, so keep the newest threshold+1
+	if len(state.times) > h.threshold+1 {
+		state.times = state.times[len(state.times)-h.threshold-1:]
+	}
+	h.prune(state, now)
+
+	var alert *Alert
+	if !state.firing && len(state.times) > h.threshold {
+		state.firing = true
+		alert = h.alert(key, state, now)
+	}
+	h.mu.Unlock()
+
+	if alert != nil && h.fn != nil {
+		h.fn(*alert)
+	}
+}
+
+// check resolves alerts whose count has fallen to the resolve threshold
+func (h *AlertHandler) check() {
+	h.mu.Lock()
+	now := clockNow(h.clock)
+	var alerts []Alert
+	for key, state := range h.states {
+		h.prune(state, now)
+		if state.firing && len(state.times) <= h.resolve {
+			state.firing = false
+			alerts = append(alerts, *h.alert(key, state, now))
+		}
+		if !state.firing && len(state.times) == 0 {
+			delete(h.states, key)
+		}
+	}
+	h.mu.Unlock()
+
+	if h.fn == nil {
+		return
+	}
+	for _, alert := range alerts {
+		h.fn(alert)
+	}
+}
+
+// prune drops entry times that left the window; h.mu must be held
+func (h *AlertHandler) prune(state *alertState, now time.Time) {
+	cutoff := now.Add(-h.window)
+	i := 0
+	for i < len(state.times) && !state.times[i].After(cutoff) {
+		i++
+	}
+	state.times = state.times[i:]
+}
+
+// alert builds the alert for state; h.mu must be held
+func (h *AlertHandler) alert(key string, state *alertState, now time.Time) *Alert {
+	return &Alert{
+		Firing:    state.firing,
+		Key:       key,
+		Count:     len(state.times),
+		Threshold: h.threshold,
+		Window:    h.window,
+		Entry:     state.last,
+		Time:      now,
+	}
+}
+
+// WithFields implements the Handler interface
+func (h *AlertHandler) WithFields(fields []Field) Handler {
+	return h
+}
+
+// Flush flushes the inner handler
+func (h *AlertHandler) Flush() error {
+	if f, ok := h.inner.(Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// Drain implements the Drainer interface for an asynchronous inner handler
+func (h *AlertHandler) Drain(ctx context.Context) (int, error) {
+	h.stopped.Do(func() { close(h.stop) })
+	if d, ok := h.inner.(Drainer); ok {
+		return d.Drain(ctx)
+	}
+	return 0, nil
+}
+
+// Close stops the background goroutine and closes the inner handler
+func (h *AlertHandler) Close() error {
+	h.stopped.Do(func() { close(h.stop) })
+	err := h.Flush()
+	if c, ok := h.inner.(io.Closer); ok {
+		err = errors.Join(err, c.Close())
+	}
+	return err
+}
+
+// AlertToHandler returns an AlertFunc that writes each alert as an entry to handler,
+// e.g. a webhook or chat handler: Error level when firing, Info when resolved
+func AlertToHandler(handler Handler) AlertFunc {
+	return func(alert Alert) {
+		entry := Entry{
+			Time:  alert.Time,
+			Level: ErrorLevel,
+			Message: fmt.Sprintf("alert: more than %d %s entries in last %s",
+				alert.Threshold, alert.Entry.Level, alert.Window),
+			Fields: []Field{
+				Int("count", alert.Count),
+				Int("threshold", alert.Threshold),
+				Duration("window", alert.Window),
+				String("last_message", alert.Entry.Message),
+			},
+		}
+		if !alert.Firing {
+			entry.Level = InfoLevel
+			entry.Message = fmt.Sprintf("resolved: %d %s entries in last %s",
+				alert.Count, alert.Entry.Level, alert.Window)
+		}
+		if alert.Key != "" {
+			entry.Fields = append(entry.Fields, String("fingerprint", alert.Key))
+		}
+		if err := handler.Handle(entry); err != nil {
+			reportError(nil, err, entry)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package logpy
+
+import "io"
+
+// ansiStripWriter removes ANSI escape sequences before writing to w
+type ansiStripWriter struct {
+	w io.Writer
+}
+
+// Write implements io.Writer, reporting len(p) on success so callers
+// are not confused by the shorter stripped output
+func (s *ansiStripWriter) Write(p []byte) (int, error) {
+	if _, err := s.w.Write(stripANSI(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// stripANSI returns p with CSI escape sequences (ESC [ ... final byte) removed
+func stripANSI(p []byte) []byte {
+	out := make([]byte, 0, len(p))
+	for i := 0; i < len(p); i++ {
+		if p[i] == 0x1b && i+1 < len(p) && p[i+1] == '[' {
+			// Skip parameter and intermediate bytes up to the final byte (0x40-0x7E)
+			j := i + 2
+			for j < len(p) && (p[j] < 0x40 || p[j] > 0x7e) {
+				j++
+			}
+			i = j
+			continue
+		}
+		out = append(out, p[i])
+	}
+	return out
+}
@@ -0,0 +1,102 @@
+package logpy
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ApacheFormat selects the Apache access log layout
+type ApacheFormat string
+
+const (
+	ApacheCommon   ApacheFormat = "common"   // %h %l %u %t "%r" %>s %b
+	ApacheCombined ApacheFormat = "combined" // common plus "%{Referer}i" "%{User-agent}i"
+)
+
+// apacheTimeLayout is the layout of %t, e.g. [10/Oct/2000:13:55:36 -0700]
+const apacheTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// ApacheFormatter formats access log entries as Apache common or combined log lines,
+// for analyzers such as GoAccess or AWStats. Values are read from the access field keys
+// written by requestid.AccessLog: client_ip, user, method, path, query, proto, status,
+// bytes, referer and user_agent. Missing values are written as "-"
+type ApacheFormatter struct {
+	Layout ApacheFormat // Defaults to combined
+}
+
+// Format implements the Formatter interface for Apache output
+func (f *ApacheFormatter) Format(entry Entry) ([]byte, error) {
+	value := func(key string) string {
+		if field, ok := findField(entry, key); ok {
+			if s := fmt.Sprint(field.Value); s != "" {
+				return s
+			}
+		}
+		return "-"
+	}
+
+	// Request line: "GET /path?query HTTP/1.1"
+	target := value(AccessPath)
+	if query := value(AccessQuery); query != "-" {
+		target += "?" + query
+	}
+	proto := value(AccessProto)
+	if proto == "-" {
+		proto = "HTTP/1.1"
+	}
+	request := value(AccessMethod) + " " + target + " " + proto
+
+	bytes := value(AccessBytes)
+	if bytes == "0" {
+		bytes = "-"
+	}
+
+	line := fmt.Sprintf("%s - %s [%s] %s %s %s",
+		apacheEscape(value(AccessClientIP)), apacheEscape(value(AccessUser)), entry.Time.Format(apacheTimeLayout),
+		apacheQuote(request), apacheEscape(value(AccessStatus)), apacheEscape(bytes))
+	if f.Layout != ApacheCommon {
+		line += " " + apacheQuote(value(AccessReferer)) + " " + apacheQuote(value(AccessUserAgent))
+	}
+	return []byte(line + "\n"), nil
+}
+
+// apacheQuote wraps s in double quotes, escaped by apacheEscape
+func apacheQuote(s string) string {
+	return `"` + apacheEscape(s) + `"`
+}
+
+// apacheEscape escapes quotes and backslashes like Apache and control characters
+// as \xHH, so a request cannot forge or split log lines
+func apacheEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c == 0x7f:
+			fmt.Fprintf(&b, `\x%02x`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// ApacheHandler writes Apache common or combined access log lines
+type ApacheHandler struct {
+	*baseHandler
+}
+
+// NewApacheHandler creates a handler writing access log lines in format to writer
+// Every entry is written, regardless of level
+func NewApacheHandler(writer io.Writer, format ApacheFormat) *ApacheHandler {
+	return &ApacheHandler{
+		baseHandler: &baseHandler{
+			level:     DebugLevel,
+			formatter: &ApacheFormatter{Layout: format},
+			writer:    writer,
+		},
+	}
+}
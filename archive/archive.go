@@ -0,0 +1,80 @@
+// Package archive provides logpy.Archiver implementations that upload rotated
+// log files to object storage (Amazon S3 and compatibles, Google Cloud Storage
+// and Azure Blob Storage) using only the standard library.
+//
+// Example:
+//
+//	cfg := logpy.DefaultConfig()
+//	cfg.Archiver = &archive.S3Archiver{
+//		Region:    "eu-west-1",
+//		Bucket:    "my-logs",
+//		Prefix:    "api/",
+//		AccessKey: os.Getenv("AWS_ACCESS_KEY_ID"),
+//		SecretKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+//		SSE:       "AES256",
+//	}
+//	cfg.ArchiveDeleteLocal = true
+package archive
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nhatpy/logpy"
+)
+
+// defaultClient is used when an archiver has no HTTP client configured
+var defaultClient = &http.Client{Timeout: 5 * time.Minute}
+
+// uploadClient builds an archiver's HTTP client once, so uploads share connections
+type uploadClient struct {
+	once   sync.Once
+	client *http.Client
+	err    error
+}
+
+// get returns client when set, else a client built from options, else the default client
+// Options without a timeout get the default client's timeout, which suits large uploads
+func (c *uploadClient) get(client *http.Client, options *logpy.HTTPOptions) (*http.Client, error) {
+	if client != nil {
+		return client, nil
+	}
+	if options == nil {
+		return defaultClient, nil
+	}
+	c.once.Do(func() {
+		opts := *options
+		if opts.Timeout <= 0 {
+			opts.Timeout = defaultClient.Timeout
+		}
+		c.client, c.err = opts.Client()
+	})
+	return c.client, c.err
+}
+
+// objectKey builds the object name for a local file under prefix
+func objectKey(prefix, path string) string {
+	return prefix + filepath.Base(path)
+}
+
+// contentType returns the MIME type for a log file
+func contentType(path string) string {
+	if strings.HasSuffix(path, ".gz") {
+		return "application/gzip"
+	}
+	return "text/plain; charset=utf-8"
+}
+
+// checkResponse converts a non-2xx response into an error
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("upload failed: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+}
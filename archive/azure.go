@@ -0,0 +1,81 @@
+package archive
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/nhatpy/logpy"
+)
+
+// AzureBlobArchiver uploads files to Azure Blob Storage using a SAS token
+type AzureBlobArchiver struct {
+	// ContainerURL is the container endpoint, e.g. https://account.blob.core.windows.net/logs
+	ContainerURL string
+	// SASToken is a shared access signature with create/write permission (with or without leading "?")
+	SASToken string
+	// Prefix is prepended to the file name to form the blob name (e.g. "api/")
+	Prefix string
+	// EncryptionScope selects a customer-managed encryption scope (optional)
+	EncryptionScope string
+
+	// Client is the HTTP client used for uploads (a default client when nil)
+	Client *http.Client
+	// HTTP configures a proxy, TLS, extra headers or gateway credentials for uploads
+	// when Client is nil; the upload keeps its own Authorization header
+	HTTP *logpy.HTTPOptions
+
+	uploadClient uploadClient
+}
+
+// Archive implements logpy.Archiver
+func (a *AzureBlobArchiver) Archive(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	blobURL := strings.TrimSuffix(a.ContainerURL, "/") + "/" + escapeBlobName(objectKey(a.Prefix, path))
+	if token := strings.TrimPrefix(a.SASToken, "?"); token != "" {
+		blobURL += "?" + token
+	}
+
+	req, err := http.NewRequest(http.MethodPut, blobURL, f)
+	if err != nil {
+		return fmt.Errorf("invalid container URL: %w", err)
+	}
+	req.ContentLength = info.Size()
+	req.Header.Set("Content-Type", contentType(path))
+	req.Header.Set("X-Ms-Blob-Type", "BlockBlob")
+	if a.EncryptionScope != "" {
+		req.Header.Set("X-Ms-Encryption-Scope", a.EncryptionScope)
+	}
+
+	client, err := a.uploadClient.get(a.Client, a.HTTP)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkResponse(resp)
+}
+
+// escapeBlobName URI-encodes each segment of a blob name
+func escapeBlobName(name string) string {
+	segments := strings.Split(name, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
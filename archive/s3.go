@@ -0,0 +1,217 @@
+package archive
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nhatpy/logpy"
+)
+
+// S3Archiver uploads files to Amazon S3 or any S3-compatible store
+// (MinIO, Cloudflare R2, GCS interoperability mode) using Signature Version 4
+type S3Archiver struct {
+	// Endpoint is the service URL; empty means https://s3.<Region>.amazonaws.com
+	Endpoint string
+	Region   string
+	Bucket   string
+	// Prefix is prepended to the file name to form the object key (e.g. "api/")
+	Prefix string
+
+	AccessKey    string
+	SecretKey    string
+	SessionToken string // Optional, for temporary credentials
+
+	// SSE sets server-side encryption: "AES256" or "aws:kms"
+	SSE string
+	// SSEKMSKeyID selects the KMS key when SSE is "aws:kms"
+	SSEKMSKeyID string
+
+	// Client is the HTTP client used for uploads (a default client when nil)
+	Client *http.Client
+	// HTTP configures a proxy, TLS, extra headers or gateway credentials for uploads
+	// when Client is nil; the upload keeps its own Authorization header
+	HTTP *logpy.HTTPOptions
+
+	uploadClient uploadClient
+}
+
+// NewGCSArchiver creates an archiver for Google Cloud Storage using HMAC keys
+// through the S3-compatible XML API
+func NewGCSArchiver(bucket, prefix, accessKey, secretKey string) *S3Archiver {
+	return &S3Archiver{
+		Endpoint:  "https://storage.googleapis.com",
+		Region:    "auto",
+		Bucket:    bucket,
+		Prefix:    prefix,
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+	}
+}
+
+// Archive implements logpy.Archiver
+func (a *S3Archiver) Archive(path string) error {
+	payloadHash, size, err := hashFile(path)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	endpoint := a.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", a.Region)
+	}
+	base, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid endpoint: %w", err)
+	}
+
+	// Path-style addressing works across S3 and compatible stores
+	key := objectKey(a.Prefix, path)
+	target := *base
+	target.Path = strings.TrimSuffix(base.Path, "/") + "/" + a.Bucket + "/" + key
+	target.RawPath = strings.TrimSuffix(base.EscapedPath(), "/") + "/" + escapePath(a.Bucket) + "/" + escapePath(key)
+
+	req, err := http.NewRequest(http.MethodPut, target.String(), f)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType(path))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if a.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", a.SessionToken)
+	}
+	if a.SSE != "" {
+		req.Header.Set("X-Amz-Server-Side-Encryption", a.SSE)
+	}
+	if a.SSEKMSKeyID != "" {
+		req.Header.Set("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id", a.SSEKMSKeyID)
+	}
+	a.sign(req, target.RawPath, payloadHash, time.Now().UTC())
+
+	client, err := a.uploadClient.get(a.Client, a.HTTP)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkResponse(resp)
+}
+
+// sign adds the AWS Signature Version 4 Authorization header to req
+func (a *S3Archiver) sign(req *http.Request, canonicalURI, payloadHash string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	// Canonical headers: host plus every x-amz-* and content-type header, sorted
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") || lower == "content-type" {
+			headers[lower] = strings.TrimSpace(values[0])
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI,
+		"", // No query string
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + a.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+a.SecretKey), date)
+	key = hmacSHA256(key, a.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		a.AccessKey, scope, signedHeaders, signature,
+	))
+}
+
+// hashFile returns the hex SHA-256 and size of the file at path
+func hashFile(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+// escapePath URI-encodes an object path as required by SigV4: every byte outside
+// A-Z, a-z, 0-9, '-', '.', '_' and '~' is percent-encoded, except the '/' separators
+func escapePath(p string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		if c == '/' || isUnreserved(c) {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&15])
+	}
+	return b.String()
+}
+
+// isUnreserved reports whether c is an unreserved URI character in SigV4
+func isUnreserved(c byte) bool {
+	return 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,21 @@
+package archive
+
+import "testing"
+
+func TestEscapePath(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"logs/app-2026.01.02_x~y.log.gz", "logs/app-2026.01.02_x~y.log.gz"},
+		{"a b+c", "a%20b%2Bc"},
+		{"!$&'()*,;=:@", "%21%24%26%27%28%29%2A%2C%3B%3D%3A%40"},
+		{"dir/é.log", "dir/%C3%A9.log"},
+		{"100%/x?y#z", "100%25/x%3Fy%23z"},
+	}
+	for _, tt := range tests {
+		if got := escapePath(tt.in); got != tt.want {
+			t.Errorf("escapePath(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
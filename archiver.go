@@ -0,0 +1,8 @@
+package logpy
+
+// Archiver uploads rotated log files to long-term storage
+// Implementations for S3, GCS and Azure Blob Storage live in the archive package
+type Archiver interface {
+	// Archive stores the closed log file at path
+	Archive(path string) error
+}
@@ -0,0 +1,212 @@
+package logpy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrBufferFull is returned when an entry is dropped because a handler's buffer is full
+var ErrBufferFull = errors.New("logpy: buffer full, entry dropped")
+
+// ErrHandlerClosed is returned when an entry is sent to a handler that has been shut down
+var ErrHandlerClosed = errors.New("logpy: handler closed")
+
+// Drainer is implemented by handlers that queue entries in the background
+// Drain stops accepting new entries and delivers queued ones until ctx is done,
+// returning the number of entries that were dropped
+type Drainer interface {
+	Drain(ctx context.Context) (dropped int, err error)
+}
+
+// asyncItem is either an entry to handle or a flush marker
+type asyncItem struct {
+	entry   Entry
+	flushed chan struct{} // Non-nil for flush markers
+}
+
+// AsyncHandler delivers entries to an inner handler from a background goroutine
+// so slow destinations never block the caller. When the buffer is full new
+// entries are dropped and counted instead of blocking
+type AsyncHandler struct {
+	inner   Handler
+	onError ErrorHandler
+	queue   chan asyncItem
+	done    chan struct{}
+	mu      sync.RWMutex
+	closed  bool
+	aborted atomic.Bool
+	dropped atomic.Int64
+}
+
+// NewAsyncHandler creates an asynchronous handler with room for bufferSize queued entries
+func NewAsyncHandler(inner Handler, bufferSize int) *AsyncHandler {
+	if bufferSize <= 0 {
+		bufferSize = 1024
+	}
+
+	h := &AsyncHandler{
+		inner: inner,
+		queue: make(chan asyncItem, bufferSize),
+		done:  make(chan struct{}),
+	}
+	go h.run()
+	return h
+}
+
+// run delivers queued entries until the queue is closed or draining is aborted
+func (h *AsyncHandler) run() {
+	defer close(h.done)
+	for item := range h.queue {
+		if item.flushed != nil {
+			close(item.flushed)
+			continue
+		}
+		metrics.queueDepth.Add(-1)
+		if h.aborted.Load() {
+			h.drop()
+			continue
+		}
+		if err := h.inner.Handle(item.entry); err != nil {
+			reportError(h.onError, err, item.entry)
+		}
+	}
+}
+
+// SetErrorHandler reports failures of the inner handler to fn
+// They happen in the background, so the logger's error handler never sees them
+// nil prints them to stderr. It should be called before logging
+func (h *AsyncHandler) SetErrorHandler(fn ErrorHandler) {
+	h.onError = fn
+}
+
+// Enabled implements the Handler interface
+func (h *AsyncHandler) Enabled(level Level) bool {
+	return h.inner.Enabled(level)
+}
+
+// Handle implements the Handler interface by queueing the entry
+func (h *AsyncHandler) Handle(entry Entry) error {
+	// Evaluate lazy fields on the caller's goroutine
+	entry = entry.resolveLazy()
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.closed {
+		h.drop()
+		return ErrHandlerClosed
+	}
+
+	metrics.queueDepth.Add(1)
+	select {
+	case h.queue <- asyncItem{entry: entry}:
+		return nil
+	default:
+		metrics.queueDepth.Add(-1)
+		h.drop()
+		return ErrBufferFull
+	}
+}
+
+// WithFields implements the Handler interface
+func (h *AsyncHandler) WithFields(fields []Field) Handler {
+	return h
+}
+
+// drop counts an entry that will never be delivered
+func (h *AsyncHandler) drop() {
+	h.dropped.Add(1)
+	metrics.dropped.Add(1)
+}
+
+// Dropped returns the number of entries dropped so far
+func (h *AsyncHandler) Dropped() int64 {
+	return h.dropped.Load()
+}
+
+// Flush waits until all entries queued before the call have been delivered
+func (h *AsyncHandler) Flush() error {
+	h.mu.RLock()
+	if h.closed {
+		h.mu.RUnlock()
+		return nil
+	}
+	marker := asyncItem{flushed: make(chan struct{})}
+	h.queue <- marker
+	h.mu.RUnlock()
+
+	<-marker.flushed
+	if f, ok := h.inner.(Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// Drain implements the Drainer interface
+// Entries still queued when ctx is done are discarded and counted as dropped
+func (h *AsyncHandler) Drain(ctx context.Context) (int, error) {
+	h.mu.Lock()
+	if !h.closed {
+		h.closed = true
+		close(h.queue)
+	}
+	h.mu.Unlock()
+
+	before := h.dropped.Load()
+	var err error
+	select {
+	case <-h.done:
+	case <-ctx.Done():
+		// Stop delivering and discard what is left without waiting for an
+		// in-flight delivery that may be stuck on a slow destination
+		h.aborted.Store(true)
+		for item := range h.queue {
+			if item.flushed != nil {
+				close(item.flushed)
+				continue
+			}
+			metrics.queueDepth.Add(-1)
+			h.drop()
+		}
+		err = ctx.Err()
+	}
+
+	// Let nested asynchronous handlers drain too
+	dropped := int(h.dropped.Load() - before)
+	if d, ok := h.inner.(Drainer); ok {
+		n, innerErr := d.Drain(ctx)
+		dropped += n
+		err = errors.Join(err, innerErr)
+	}
+	return dropped, err
+}
+
+// Close drains all queued entries and closes the inner handler
+// After a Drain that ran out of time (e.g. in Logger.Shutdown) it returns at once;
+// the inner handler is then closed when the delivery in flight returns
+func (h *AsyncHandler) Close() error {
+	var err error
+	if !h.aborted.Load() {
+		_, err = h.Drain(context.Background())
+	}
+	c, ok := h.inner.(io.Closer)
+	if !ok {
+		return err
+	}
+	select {
+	case <-h.done:
+		return errors.Join(err, c.Close())
+	default:
+		go func() {
+			<-h.done
+			if err := c.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "error closing log handler: %v\n", err)
+			}
+		}()
+		return err
+	}
+}
@@ -0,0 +1,195 @@
+package logpy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBatchEntries = 100
+	defaultBatchDelay   = time.Second
+)
+
+// BatchCapableHandler is implemented by transports that can deliver many entries
+// in one request (HTTP collectors, Kafka, Loki, Elasticsearch, ...)
+type BatchCapableHandler interface {
+	Handler
+	// HandleBatch delivers entries in order; the slice is not reused after the call
+	HandleBatch(entries []Entry) error
+}
+
+// BatchHandler collects entries and hands them to a BatchCapableHandler in batches.
+// A batch is sent when it reaches maxEntries or maxBytes, or maxDelay after its
+// first entry, whichever comes first. Batches are delivered in order
+type BatchHandler struct {
+	inner      BatchCapableHandler
+	maxEntries int
+	maxBytes   int
+	maxDelay   time.Duration
+	onError    ErrorHandler
+	sizer      JSONFormatter // Measures entries for maxBytes
+
+	mu     sync.Mutex
+	batch  []Entry
+	size   int // Approximate encoded size of batch
+	timer  *time.Timer
+	gen    uint64 // Incremented per sent batch, so a stale timer leaves the next batch alone
+	closed bool
+
+	sendMu sync.Mutex // Serializes delivery so batches arrive in order
+}
+
+// NewBatchHandler creates a batching handler around inner
+// maxEntries <= 0 uses 100, maxBytes <= 0 disables the size limit and
+// maxDelay <= 0 uses one second. Entry size is measured as encoded JSON
+func NewBatchHandler(inner BatchCapableHandler, maxEntries, maxBytes int, maxDelay time.Duration) *BatchHandler {
+	if maxEntries <= 0 {
+		maxEntries = defaultBatchEntries
+	}
+	if maxDelay <= 0 {
+		maxDelay = defaultBatchDelay
+	}
+	return &BatchHandler{
+		inner:      inner,
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		maxDelay:   maxDelay,
+	}
+}
+
+// SetErrorHandler reports failures of batches sent when maxDelay expires to fn
+// They happen in the background, so the logger's error handler never sees them
+// nil prints them to stderr. It should be called before logging
+func (h *BatchHandler) SetErrorHandler(fn ErrorHandler) {
+	h.onError = fn
+}
+
+// Enabled implements the Handler interface
+func (h *BatchHandler) Enabled(level Level) bool {
+	return h.inner.Enabled(level)
+}
+
+// Handle implements the Handler interface by adding the entry to the current batch
+// When the entry fills the batch, it is sent before Handle returns
+func (h *BatchHandler) Handle(entry Entry) error {
+	// Evaluate lazy fields on the caller's goroutine
+	entry = entry.resolveLazy()
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return ErrHandlerClosed
+	}
+	h.batch = append(h.batch, entry)
+	if h.maxBytes > 0 {
+		if data, err := h.sizer.Format(entry); err == nil {
+			h.size += len(data)
+		}
+	}
+
+	if len(h.batch) >= h.maxEntries || (h.maxBytes > 0 && h.size >= h.maxBytes) {
+		return h.sendLocked()
+	}
+	if h.timer == nil {
+		gen := h.gen
+		h.timer = time.AfterFunc(h.maxDelay, func() { h.expire(gen) })
+	}
+	h.mu.Unlock()
+	return nil
+}
+
+// expire sends batch gen once maxDelay has passed
+// A timer that fired while its batch was being sent finds a newer generation and stops
+func (h *BatchHandler) expire(gen uint64) {
+	h.mu.Lock()
+	if h.gen != gen {
+		h.mu.Unlock()
+		return
+	}
+	batch := h.batch
+	if err := h.sendLocked(); err != nil && len(batch) > 0 {
+		reportError(h.onError, err, batch[0])
+	}
+}
+
+// sendLocked takes the current batch and delivers it
+// It must be called with h.mu held and releases it
+func (h *BatchHandler) sendLocked() error {
+	batch := h.batch
+	h.batch = nil
+	h.size = 0
+	h.gen++
+	if h.timer != nil {
+		h.timer.Stop()
+		h.timer = nil
+	}
+
+	// Take the send lock before releasing mu so batches keep their order
+	h.sendMu.Lock()
+	defer h.sendMu.Unlock()
+	h.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+	return h.inner.HandleBatch(batch)
+}
+
+// WithFields implements the Handler interface
+// Batches are shared, fields are managed by the logger
+func (h *BatchHandler) WithFields(fields []Field) Handler {
+	return h
+}
+
+// Flush sends the current batch and flushes the inner handler
+func (h *BatchHandler) Flush() error {
+	h.mu.Lock()
+	err := h.sendLocked()
+	if f, ok := h.inner.(Flusher); ok {
+		err = errors.Join(err, f.Flush())
+	}
+	return err
+}
+
+// Drain implements the Drainer interface
+// It stops accepting entries, sends the current batch and drains the inner handler
+// If ctx is done while the batch is being delivered, Drain returns its size as
+// dropped without waiting for HandleBatch
+func (h *BatchHandler) Drain(ctx context.Context) (int, error) {
+	h.mu.Lock()
+	h.closed = true
+	pending := len(h.batch)
+	h.mu.Unlock()
+
+	sent := make(chan error, 1)
+	go func() {
+		h.mu.Lock()
+		sent <- h.sendLocked()
+	}()
+	var err error
+	select {
+	case err = <-sent:
+	case <-ctx.Done():
+		return pending, ctx.Err()
+	}
+
+	var dropped int
+	if d, ok := h.inner.(Drainer); ok {
+		n, innerErr := d.Drain(ctx)
+		dropped += n
+		err = errors.Join(err, innerErr)
+	}
+	return dropped, err
+}
+
+// Close sends the current batch and closes the inner handler
+func (h *BatchHandler) Close() error {
+	_, err := h.Drain(context.Background())
+	if c, ok := h.inner.(io.Closer); ok {
+		err = errors.Join(err, c.Close())
+	}
+	return err
+}
@@ -0,0 +1,182 @@
+package logpy
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingBatchHandler records delivered batches and can block deliveries
+type recordingBatchHandler struct {
+	mu      sync.Mutex
+	batches [][]Entry
+	block   chan struct{} // When non-nil, HandleBatch waits for it to be closed
+	closed  bool
+}
+
+func (r *recordingBatchHandler) Enabled(Level) bool                { return true }
+func (r *recordingBatchHandler) Handle(entry Entry) error          { return r.HandleBatch([]Entry{entry}) }
+func (r *recordingBatchHandler) WithFields(fields []Field) Handler { return r }
+
+func (r *recordingBatchHandler) HandleBatch(entries []Entry) error {
+	if r.block != nil {
+		<-r.block
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, entries)
+	return nil
+}
+
+func (r *recordingBatchHandler) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	return nil
+}
+
+// sizes returns the number of entries in each delivered batch
+func (r *recordingBatchHandler) sizes() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sizes := make([]int, len(r.batches))
+	for i, batch := range r.batches {
+		sizes[i] = len(batch)
+	}
+	return sizes
+}
+
+func handleN(t *testing.T, h Handler, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if err := h.Handle(Entry{Level: InfoLevel, Message: "entry", Time: time.Now()}); err != nil {
+			t.Fatalf("Handle: %v", err)
+		}
+	}
+}
+
+func equalSizes(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestBatchHandlerSendsFullBatches(t *testing.T) {
+	inner := &recordingBatchHandler{}
+	h := NewBatchHandler(inner, 3, 0, time.Hour)
+
+	handleN(t, h, 7)
+	if got := inner.sizes(); !equalSizes(got, []int{3, 3}) {
+		t.Fatalf("batches = %v, want [3 3]", got)
+	}
+}
+
+func TestBatchHandlerSendsAtMaxBytes(t *testing.T) {
+	inner := &recordingBatchHandler{}
+	h := NewBatchHandler(inner, 100, 1, time.Hour)
+
+	handleN(t, h, 2)
+	if got := inner.sizes(); !equalSizes(got, []int{1, 1}) {
+		t.Fatalf("batches = %v, want [1 1]", got)
+	}
+}
+
+func TestBatchHandlerSendsAfterMaxDelay(t *testing.T) {
+	inner := &recordingBatchHandler{}
+	h := NewBatchHandler(inner, 100, 0, 20*time.Millisecond)
+
+	handleN(t, h, 2)
+	deadline := time.Now().Add(2 * time.Second)
+	for len(inner.sizes()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := inner.sizes(); !equalSizes(got, []int{2}) {
+		t.Fatalf("batches = %v, want [2]", got)
+	}
+}
+
+func TestBatchHandlerStaleTimerKeepsNewBatch(t *testing.T) {
+	inner := &recordingBatchHandler{}
+	h := NewBatchHandler(inner, 100, 0, time.Hour)
+
+	handleN(t, h, 1)
+	h.mu.Lock()
+	staleGen := h.gen
+	h.mu.Unlock()
+	if err := h.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	// A timer for the flushed batch firing late must not send the new one early
+	handleN(t, h, 1)
+	h.expire(staleGen)
+	if got := inner.sizes(); !equalSizes(got, []int{1}) {
+		t.Fatalf("batches = %v, want [1]", got)
+	}
+}
+
+func TestBatchHandlerDrain(t *testing.T) {
+	inner := &recordingBatchHandler{}
+	h := NewBatchHandler(inner, 100, 0, time.Hour)
+
+	handleN(t, h, 5)
+	dropped, err := h.Drain(context.Background())
+	if err != nil || dropped != 0 {
+		t.Fatalf("Drain = %d, %v, want 0, nil", dropped, err)
+	}
+	if got := inner.sizes(); !equalSizes(got, []int{5}) {
+		t.Fatalf("batches = %v, want [5]", got)
+	}
+	if err := h.Handle(Entry{Message: "late"}); !errors.Is(err, ErrHandlerClosed) {
+		t.Fatalf("Handle after Drain = %v, want ErrHandlerClosed", err)
+	}
+}
+
+func TestBatchHandlerDrainRespectsContext(t *testing.T) {
+	inner := &recordingBatchHandler{block: make(chan struct{})}
+	defer close(inner.block)
+	h := NewBatchHandler(inner, 100, 0, time.Hour)
+
+	handleN(t, h, 4)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	dropped, err := h.Drain(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Drain error = %v, want context.DeadlineExceeded", err)
+	}
+	if dropped != 4 {
+		t.Fatalf("dropped = %d, want 4", dropped)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Drain returned after %v, want about 50ms", elapsed)
+	}
+}
+
+func TestBatchHandlerClose(t *testing.T) {
+	inner := &recordingBatchHandler{}
+	h := NewBatchHandler(inner, 100, 0, time.Hour)
+
+	handleN(t, h, 2)
+	if err := h.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if got := inner.sizes(); !equalSizes(got, []int{2}) {
+		t.Fatalf("batches = %v, want [2]", got)
+	}
+	inner.mu.Lock()
+	closed := inner.closed
+	inner.mu.Unlock()
+	if !closed {
+		t.Fatal("inner handler was not closed")
+	}
+}
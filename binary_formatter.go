@@ -0,0 +1,417 @@
+package logpy
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// BinaryEncoding selects the wire format of a BinaryFormatter
+type BinaryEncoding string
+
+const (
+	EncodingMsgpack BinaryEncoding = "msgpack" // MessagePack, e.g. for fluentd forward input
+	EncodingCBOR    BinaryEncoding = "cbor"    // CBOR (RFC 8949)
+)
+
+// BinaryFormatter formats entries as MessagePack or CBOR maps with the same keys
+// and order as JSONFormatter. Each entry is a self-delimiting value, so entries can
+// be written back to back to a stream
+type BinaryFormatter struct {
+	Encoding          BinaryEncoding    // Defaults to MessagePack
+	TimestampFormat   string            // Layout for string timestamps (default RFC 3339)
+	TimestampEncoding TimestampEncoding // rfc3339 (default) or epoch seconds/millis/nanos/float
+	TimeEncoding      TimestampEncoding // Time fields, like JSONFormatter.TimeEncoding
+	DurationEncoding  DurationEncoding  // Defaults to nanoseconds, like JSON
+	LevelEncoding     LevelEncoding     // Upper-case name (default), lower-case name, number or severity
+	LevelLabels       map[Level]string  // Custom level names, e.g. {WarnLevel: "WARNING"}
+	AddCaller         bool
+}
+
+// Format implements the Formatter interface for binary output
+func (f *BinaryFormatter) Format(entry Entry) ([]byte, error) {
+	var enc binaryEncoder
+	if f.Encoding == EncodingCBOR {
+		enc = &cborEncoder{}
+	} else {
+		enc = &msgpackEncoder{}
+	}
+
+	// Map headers carry their length up front
+	fields := topLevelFields(entry.Fields)
+	_, contextFields := applyDuplicateKeyPolicy(nil, entry.ContextFields, DuplicateKeepLast)
+	size := 2 + len(fields)
+	if f.AddCaller && entry.Caller.File != "" {
+		size++
+	}
+	if entry.Message != "" {
+		size++
+	}
+	if len(contextFields) > 0 {
+		size++
+	}
+	enc.mapHeader(size)
+
+	// Timestamps and durations are encoded like the JSON formatter
+	jf := JSONFormatter{
+		TimestampFormat:   f.TimestampFormat,
+		TimestampEncoding: f.TimestampEncoding,
+		TimeEncoding:      f.TimeEncoding,
+		DurationEncoding:  f.DurationEncoding,
+	}
+	enc.str("timestamp")
+	if err := encodeBinaryValue(enc, jf.encodeTimestamp(entry.Time)); err != nil {
+		return nil, err
+	}
+
+	enc.str("level")
+	if err := encodeBinaryValue(enc, encodeLevel(entry.Level, f.LevelEncoding, f.LevelLabels)); err != nil {
+		return nil, err
+	}
+
+	if f.AddCaller && entry.Caller.File != "" {
+		enc.str("caller")
+		enc.str(fmt.Sprintf("%s:%d", entry.Caller.File, entry.Caller.Line))
+	}
+
+	if entry.Message != "" {
+		enc.str("message")
+		enc.str(entry.Message)
+	}
+
+	for _, field := range fields {
+		enc.str(field.Key)
+		if err := encodeBinaryValue(enc, binaryFieldValue(&jf, field)); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(contextFields) > 0 {
+		enc.str("context")
+		enc.mapHeader(len(contextFields))
+		for _, field := range contextFields {
+			enc.str(field.Key)
+			if err := encodeBinaryValue(enc, binaryFieldValue(&jf, field)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return enc.bytes(), nil
+}
+
+// binaryFieldValue returns the value of a field, replacing an Any value that cannot
+// be encoded so the rest of the entry is kept
+func binaryFieldValue(jf *JSONFormatter, field Field) interface{} {
+	value := jf.fieldValue(field)
+	if field.Type == AnyType {
+		return safeJSONValue(field.Key, value)
+	}
+	return value
+}
+
+// binaryEncoder writes the value types shared by MessagePack and CBOR
+type binaryEncoder interface {
+	mapHeader(n int)
+	arrayHeader(n int)
+	str(s string)
+	int(v int64)
+	uint(v uint64)
+	float(v float64)
+	bool(v bool)
+	null()
+	bytes() []byte
+}
+
+// encodeBinaryValue writes a field value, falling back to its JSON representation
+// for types without a direct binary mapping (structs, custom maps, ...)
+func encodeBinaryValue(enc binaryEncoder, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		enc.null()
+	case string:
+		enc.str(v)
+	case bool:
+		enc.bool(v)
+	case int:
+		enc.int(int64(v))
+	case int8:
+		enc.int(int64(v))
+	case int16:
+		enc.int(int64(v))
+	case int32:
+		enc.int(int64(v))
+	case int64:
+		enc.int(v)
+	case uint:
+		enc.uint(uint64(v))
+	case uint8:
+		enc.uint(uint64(v))
+	case uint16:
+		enc.uint(uint64(v))
+	case uint32:
+		enc.uint(uint64(v))
+	case uint64:
+		enc.uint(v)
+	case float32:
+		enc.float(float64(v))
+	case float64:
+		enc.float(v)
+	case time.Time:
+		enc.str(v.Format(time.RFC3339Nano))
+	case time.Duration:
+		enc.int(int64(v))
+	case error:
+		enc.str(safeError(v))
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			enc.int(i)
+		} else {
+			fv, _ := v.Float64()
+			enc.float(fv)
+		}
+	case []interface{}:
+		enc.arrayHeader(len(v))
+		for _, item := range v {
+			if err := encodeBinaryValue(enc, item); err != nil {
+				return err
+			}
+		}
+	case []string:
+		enc.arrayHeader(len(v))
+		for _, item := range v {
+			enc.str(item)
+		}
+	case []int:
+		enc.arrayHeader(len(v))
+		for _, item := range v {
+			enc.int(int64(item))
+		}
+	case []float64:
+		enc.arrayHeader(len(v))
+		for _, item := range v {
+			enc.float(item)
+		}
+	case []bool:
+		enc.arrayHeader(len(v))
+		for _, item := range v {
+			enc.bool(item)
+		}
+	case map[string]interface{}:
+		// Sort keys so output is deterministic
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		enc.mapHeader(len(keys))
+		for _, key := range keys {
+			enc.str(key)
+			if err := encodeBinaryValue(enc, v[key]); err != nil {
+				return err
+			}
+		}
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		var generic interface{}
+		if err := dec.Decode(&generic); err != nil {
+			return err
+		}
+		return encodeBinaryValue(enc, generic)
+	}
+	return nil
+}
+
+// msgpackEncoder writes MessagePack values
+type msgpackEncoder struct {
+	buf bytes.Buffer
+}
+
+func (e *msgpackEncoder) mapHeader(n int) {
+	switch {
+	case n < 16:
+		e.buf.WriteByte(0x80 | byte(n))
+	case n <= math.MaxUint16:
+		e.buf.WriteByte(0xde)
+		e.buf.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	default:
+		e.buf.WriteByte(0xdf)
+		e.buf.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	}
+}
+
+func (e *msgpackEncoder) arrayHeader(n int) {
+	switch {
+	case n < 16:
+		e.buf.WriteByte(0x90 | byte(n))
+	case n <= math.MaxUint16:
+		e.buf.WriteByte(0xdc)
+		e.buf.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	default:
+		e.buf.WriteByte(0xdd)
+		e.buf.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	}
+}
+
+func (e *msgpackEncoder) str(s string) {
+	n := len(s)
+	switch {
+	case n < 32:
+		e.buf.WriteByte(0xa0 | byte(n))
+	case n <= math.MaxUint8:
+		e.buf.WriteByte(0xd9)
+		e.buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		e.buf.WriteByte(0xda)
+		e.buf.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	default:
+		e.buf.WriteByte(0xdb)
+		e.buf.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	}
+	e.buf.WriteString(s)
+}
+
+func (e *msgpackEncoder) int(v int64) {
+	switch {
+	case v >= 0:
+		e.uint(uint64(v))
+	case v >= -32:
+		e.buf.WriteByte(byte(v)) // Negative fixint
+	case v >= math.MinInt8:
+		e.buf.WriteByte(0xd0)
+		e.buf.WriteByte(byte(v))
+	case v >= math.MinInt16:
+		e.buf.WriteByte(0xd1)
+		e.buf.Write(binary.BigEndian.AppendUint16(nil, uint16(v)))
+	case v >= math.MinInt32:
+		e.buf.WriteByte(0xd2)
+		e.buf.Write(binary.BigEndian.AppendUint32(nil, uint32(v)))
+	default:
+		e.buf.WriteByte(0xd3)
+		e.buf.Write(binary.BigEndian.AppendUint64(nil, uint64(v)))
+	}
+}
+
+func (e *msgpackEncoder) uint(v uint64) {
+	switch {
+	case v < 128:
+		e.buf.WriteByte(byte(v)) // Positive fixint
+	case v <= math.MaxUint8:
+		e.buf.WriteByte(0xcc)
+		e.buf.WriteByte(byte(v))
+	case v <= math.MaxUint16:
+		e.buf.WriteByte(0xcd)
+		e.buf.Write(binary.BigEndian.AppendUint16(nil, uint16(v)))
+	case v <= math.MaxUint32:
+		e.buf.WriteByte(0xce)
+		e.buf.Write(binary.BigEndian.AppendUint32(nil, uint32(v)))
+	default:
+		e.buf.WriteByte(0xcf)
+		e.buf.Write(binary.BigEndian.AppendUint64(nil, v))
+	}
+}
+
+func (e *msgpackEncoder) float(v float64) {
+	e.buf.WriteByte(0xcb)
+	e.buf.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(v)))
+}
+
+func (e *msgpackEncoder) bool(v bool) {
+	if v {
+		e.buf.WriteByte(0xc3)
+	} else {
+		e.buf.WriteByte(0xc2)
+	}
+}
+
+func (e *msgpackEncoder) null() {
+	e.buf.WriteByte(0xc0)
+}
+
+func (e *msgpackEncoder) bytes() []byte {
+	return e.buf.Bytes()
+}
+
+// CBOR major types
+const (
+	cborUnsigned = 0 << 5
+	cborNegative = 1 << 5
+	cborText     = 3 << 5
+	cborArray    = 4 << 5
+	cborMap      = 5 << 5
+)
+
+// cborEncoder writes CBOR values
+type cborEncoder struct {
+	buf bytes.Buffer
+}
+
+// head writes a major type with its argument in the shortest form
+func (e *cborEncoder) head(major byte, n uint64) {
+	switch {
+	case n < 24:
+		e.buf.WriteByte(major | byte(n))
+	case n <= math.MaxUint8:
+		e.buf.WriteByte(major | 24)
+		e.buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		e.buf.WriteByte(major | 25)
+		e.buf.Write(binary.BigEndian.AppendUint16(nil, uint16(n)))
+	case n <= math.MaxUint32:
+		e.buf.WriteByte(major | 26)
+		e.buf.Write(binary.BigEndian.AppendUint32(nil, uint32(n)))
+	default:
+		e.buf.WriteByte(major | 27)
+		e.buf.Write(binary.BigEndian.AppendUint64(nil, n))
+	}
+}
+
+func (e *cborEncoder) mapHeader(n int)   { e.head(cborMap, uint64(n)) }
+func (e *cborEncoder) arrayHeader(n int) { e.head(cborArray, uint64(n)) }
+
+func (e *cborEncoder) str(s string) {
+	e.head(cborText, uint64(len(s)))
+	e.buf.WriteString(s)
+}
+
+func (e *cborEncoder) int(v int64) {
+	if v >= 0 {
+		e.head(cborUnsigned, uint64(v))
+		return
+	}
+	e.head(cborNegative, uint64(-1-v))
+}
+
+func (e *cborEncoder) uint(v uint64) {
+	e.head(cborUnsigned, v)
+}
+
+func (e *cborEncoder) float(v float64) {
+	e.buf.WriteByte(0xfb)
+	e.buf.Write(binary.BigEndian.AppendUint64(nil, math.Float64bits(v)))
+}
+
+func (e *cborEncoder) bool(v bool) {
+	if v {
+		e.buf.WriteByte(0xf5)
+	} else {
+		e.buf.WriteByte(0xf4)
+	}
+}
+
+func (e *cborEncoder) null() {
+	e.buf.WriteByte(0xf6)
+}
+
+func (e *cborEncoder) bytes() []byte {
+	return e.buf.Bytes()
+}
@@ -0,0 +1,244 @@
+package logpy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrCircuitOpen is returned when an entry is dropped because a circuit breaker is open
+// and no fallback handler is configured
+var ErrCircuitOpen = errors.New("logpy: circuit open, entry dropped")
+
+const (
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 30 * time.Second
+)
+
+// CircuitState is the state of a CircuitBreakerHandler
+type CircuitState int
+
+const (
+	CircuitClosed   CircuitState = iota // Entries go to the inner handler
+	CircuitOpen                         // Delivery is not attempted until the cool-down ends
+	CircuitHalfOpen                     // One trial entry is in flight
+)
+
+// String returns the name of the state
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerHandler stops calling a failing handler for a cool-down period, so a
+// down log backend does not add its latency to every log call. While open, entries
+// go to the fallback handler if one is set and are dropped otherwise. After the
+// cool-down a single trial entry is sent; success closes the circuit, failure reopens it
+type CircuitBreakerHandler struct {
+	inner     Handler
+	fallback  Handler // Optional
+	threshold int
+	cooldown  time.Duration
+	dropped   atomic.Int64
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int       // Consecutive inner failures while closed
+	openedAt time.Time // When the circuit last opened
+}
+
+// NewCircuitBreakerHandler creates a circuit breaker around inner that opens after 5
+// consecutive errors and stays open for 30 seconds. fallback may be nil
+func NewCircuitBreakerHandler(inner, fallback Handler) *CircuitBreakerHandler {
+	return &CircuitBreakerHandler{
+		inner:     inner,
+		fallback:  fallback,
+		threshold: defaultBreakerThreshold,
+		cooldown:  defaultBreakerCooldown,
+	}
+}
+
+// SetThreshold sets how many consecutive failures open the circuit
+func (h *CircuitBreakerHandler) SetThreshold(n int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if n <= 0 {
+		n = defaultBreakerThreshold
+	}
+	h.threshold = n
+}
+
+// SetCooldown sets how long the circuit stays open before a trial entry is sent
+func (h *CircuitBreakerHandler) SetCooldown(d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if d <= 0 {
+		d = defaultBreakerCooldown
+	}
+	h.cooldown = d
+}
+
+// State returns the current circuit state
+func (h *CircuitBreakerHandler) State() CircuitState {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.state == CircuitOpen && time.Since(h.openedAt) >= h.cooldown {
+		// The next entry will be the trial
+		return CircuitHalfOpen
+	}
+	return h.state
+}
+
+// Dropped returns the number of entries dropped while the circuit was open
+func (h *CircuitBreakerHandler) Dropped() int64 {
+	return h.dropped.Load()
+}
+
+// Enabled implements the Handler interface
+func (h *CircuitBreakerHandler) Enabled(level Level) bool {
+	return h.inner.Enabled(level) || (h.fallback != nil && h.fallback.Enabled(level))
+}
+
+// Handle implements the Handler interface
+func (h *CircuitBreakerHandler) Handle(entry Entry) error {
+	// Evaluate lazy fields once so the fallback sees the same value
+	entry = entry.resolveLazy()
+
+	// An entry below the inner handler's level says nothing about its health, so it
+	// neither uses the half-open trial nor resets the failure count
+	if !h.inner.Enabled(entry.Level) {
+		if h.fallback != nil && h.fallback.Enabled(entry.Level) {
+			return h.fallback.Handle(entry)
+		}
+		return nil
+	}
+
+	if !h.allow() {
+		return h.divert(entry)
+	}
+
+	err := h.inner.Handle(entry)
+	h.record(err)
+	if err != nil && h.fallback != nil {
+		if fbErr := h.fallback.Handle(entry); fbErr != nil {
+			return errors.Join(err, fbErr)
+		}
+		return nil
+	}
+	return err
+}
+
+// allow reports whether the next entry should be sent to the inner handler
+func (h *CircuitBreakerHandler) allow() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	switch h.state {
+	case CircuitOpen:
+		if time.Since(h.openedAt) < h.cooldown {
+			return false
+		}
+		h.state = CircuitHalfOpen
+		return true
+	case CircuitHalfOpen:
+		// A trial is already in flight
+		return false
+	default:
+		return true
+	}
+}
+
+// record updates the circuit state after a delivery attempt
+func (h *CircuitBreakerHandler) record(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err == nil {
+		h.failures = 0
+		h.state = CircuitClosed
+		return
+	}
+	h.failures++
+	if h.state == CircuitHalfOpen || h.failures >= h.threshold {
+		h.state = CircuitOpen
+		h.openedAt = time.Now()
+	}
+}
+
+// divert sends an entry to the fallback or drops it while the circuit is open
+func (h *CircuitBreakerHandler) divert(entry Entry) error {
+	if h.fallback != nil {
+		return h.fallback.Handle(entry)
+	}
+	h.dropped.Add(1)
+	metrics.dropped.Add(1)
+	return ErrCircuitOpen
+}
+
+// WithFields implements the Handler interface
+func (h *CircuitBreakerHandler) WithFields(fields []Field) Handler {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	child := &CircuitBreakerHandler{
+		inner:     h.inner.WithFields(fields),
+		threshold: h.threshold,
+		cooldown:  h.cooldown,
+	}
+	if h.fallback != nil {
+		child.fallback = h.fallback.WithFields(fields)
+	}
+	return child
+}
+
+// handlers returns the inner and fallback handlers
+func (h *CircuitBreakerHandler) handlers() []Handler {
+	if h.fallback == nil {
+		return []Handler{h.inner}
+	}
+	return []Handler{h.inner, h.fallback}
+}
+
+// Flush flushes the inner and fallback handlers
+func (h *CircuitBreakerHandler) Flush() error {
+	var errs []error
+	for _, handler := range h.handlers() {
+		if f, ok := handler.(Flusher); ok {
+			errs = append(errs, f.Flush())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Drain implements the Drainer interface for asynchronous inner or fallback handlers
+func (h *CircuitBreakerHandler) Drain(ctx context.Context) (int, error) {
+	var dropped int
+	var errs []error
+	for _, handler := range h.handlers() {
+		if d, ok := handler.(Drainer); ok {
+			n, err := d.Drain(ctx)
+			dropped += n
+			errs = append(errs, err)
+		}
+	}
+	return dropped, errors.Join(errs...)
+}
+
+// Close closes the inner and fallback handlers
+func (h *CircuitBreakerHandler) Close() error {
+	var errs []error
+	for _, handler := range h.handlers() {
+		if c, ok := handler.(io.Closer); ok {
+			errs = append(errs, c.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
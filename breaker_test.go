@@ -0,0 +1,118 @@
+package logpy
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+var errTestBackend = errors.New("backend down")
+
+// flakyHandler fails while fail is set and counts the entries it was given
+type flakyHandler struct {
+	mu    sync.Mutex
+	level Level
+	fail  bool
+	calls int
+}
+
+func (f *flakyHandler) Enabled(level Level) bool          { return level.AtLeast(f.level) }
+func (f *flakyHandler) WithFields(fields []Field) Handler { return f }
+
+func (f *flakyHandler) Handle(entry Entry) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.fail {
+		return errTestBackend
+	}
+	return nil
+}
+
+func (f *flakyHandler) setFail(fail bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fail = fail
+}
+
+func (f *flakyHandler) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func TestCircuitBreakerStates(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int  // Failing entries sent first
+		recover   bool // Whether the inner handler works again after the cool-down
+		wantState CircuitState
+		wantCalls int // Entries that reached the inner handler, including the trial
+	}{
+		{"success below threshold stays closed", 2, true, CircuitClosed, 3},
+		{"failed trial reopens", 3, false, CircuitOpen, 4},
+		{"successful trial closes", 3, true, CircuitClosed, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &flakyHandler{fail: true}
+			h := NewCircuitBreakerHandler(inner, nil)
+			h.SetThreshold(3)
+			h.SetCooldown(time.Hour)
+
+			for i := 0; i < tt.failures; i++ {
+				h.Handle(Entry{Level: InfoLevel})
+			}
+			if tt.failures >= 3 {
+				if err := h.Handle(Entry{Level: InfoLevel}); !errors.Is(err, ErrCircuitOpen) {
+					t.Fatalf("Handle while open = %v, want ErrCircuitOpen", err)
+				}
+				h.SetCooldown(time.Millisecond)
+				time.Sleep(5 * time.Millisecond)
+			}
+
+			inner.setFail(!tt.recover)
+			h.Handle(Entry{Level: InfoLevel})
+			h.SetCooldown(time.Hour)
+			if got := h.State(); got != tt.wantState {
+				t.Errorf("State() = %s, want %s", got, tt.wantState)
+			}
+			if got := inner.callCount(); got != tt.wantCalls {
+				t.Errorf("inner calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerSkipsDisabledLevels(t *testing.T) {
+	inner := &flakyHandler{level: WarnLevel}
+	fallback := &flakyHandler{}
+	h := NewCircuitBreakerHandler(inner, fallback)
+	h.SetThreshold(1)
+
+	// A failure opens the circuit; entries below Warn must not be taken as the trial
+	inner.setFail(true)
+	h.Handle(Entry{Level: ErrorLevel})
+	h.SetCooldown(time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	inner.setFail(false)
+	if err := h.Handle(Entry{Level: DebugLevel}); err != nil {
+		t.Fatalf("Handle(Debug) = %v", err)
+	}
+	if got := inner.callCount(); got != 1 {
+		t.Fatalf("inner calls = %d, want 1: a disabled level reached the inner handler", got)
+	}
+	if got := fallback.callCount(); got != 2 {
+		t.Fatalf("fallback calls = %d, want 2", got)
+	}
+	if got := h.State(); got != CircuitHalfOpen {
+		t.Fatalf("State() = %s, want %s: the trial was used by a disabled level", got, CircuitHalfOpen)
+	}
+
+	h.Handle(Entry{Level: WarnLevel})
+	if got := h.State(); got != CircuitClosed {
+		t.Fatalf("State() after trial = %s, want %s", got, CircuitClosed)
+	}
+}
@@ -0,0 +1,29 @@
+package logpy
+
+import "time"
+
+// Clock provides the current time for timestamps and file rotation
+// Tests can substitute a fixed or manually advanced clock for deterministic output
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to the Clock interface
+// Example: logpy.ClockFunc(func() time.Time { return fixed })
+type ClockFunc func() time.Time
+
+// Now implements the Clock interface
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock is the default clock backed by time.Now
+var SystemClock Clock = ClockFunc(time.Now)
+
+// clockNow returns the time from c, or the wall clock when c is nil
+func clockNow(c Clock) time.Time {
+	if c == nil {
+		return time.Now()
+	}
+	return c.Now()
+}
@@ -0,0 +1,301 @@
+// Command logpy pretty-prints and filters log files written by logpy
+//
+// Usage:
+//
+//	logpy [flags] [file ...]
+//
+// With no files it reads standard input. Examples:
+//
+//	logpy --level warn ./logs/app.log
+//	logpy --where user=john --since 1h -f ./logs/app.log
+//	logpy -f ./logs/api.log ./logs/worker.log
+//	kubectl logs my-pod | logpy --level error
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nhatpy/logpy"
+	"github.com/nhatpy/logpy/parse"
+)
+
+// whereFlags collects repeated --where filters
+type whereFlags []string
+
+func (w *whereFlags) String() string {
+	return strings.Join(*w, ",")
+}
+
+func (w *whereFlags) Set(s string) error {
+	if !strings.Contains(s, "=") {
+		return fmt.Errorf("expected key=value or key!=value, got %q", s)
+	}
+	*w = append(*w, s)
+	return nil
+}
+
+// filter selects which entries are printed
+type filter struct {
+	level logpy.Level
+	since time.Time
+	until time.Time
+	where []condition
+}
+
+// condition is a single --where filter
+type condition struct {
+	key    string
+	value  string
+	negate bool
+}
+
+func main() {
+	var (
+		level   = flag.String("level", "debug", "minimum level to show (debug, info, warn, error)")
+		since   = flag.String("since", "", "show entries at or after this time (RFC 3339, \"2006-01-02 15:04:05\" or a duration like 1h)")
+		until   = flag.String("until", "", "show entries before this time (same formats as --since)")
+		follow  = flag.Bool("f", false, "keep reading as the file grows, like tail -f")
+		noColor = flag.Bool("no-color", false, "disable colors")
+		caller  = flag.Bool("caller", true, "show caller file and line")
+		where   whereFlags
+	)
+	flag.Var(&where, "where", "only show entries whose field matches key=value or key!=value (repeatable)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: logpy [flags] [file ...]\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	f, err := newFilter(*level, *since, *until, where)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logpy: %v\n", err)
+		os.Exit(2)
+	}
+
+	formatter := &logpy.ConsoleFormatter{
+		TimestampFormat: "2006-01-02 15:04:05",
+		UseColor:        !*noColor && os.Getenv("NO_COLOR") == "" && isTerminal(os.Stdout),
+		ColorConfig:     logpy.DefaultColorConfig(),
+	}
+
+	files := flag.Args()
+	switch {
+	case len(files) == 0:
+		err = view(os.Stdin, "stdin", f, formatter, *caller, false)
+	case *follow:
+		err = followAll(files, f, formatter, *caller)
+	default:
+		for _, name := range files {
+			var file *os.File
+			file, err = os.Open(name)
+			if err != nil {
+				break
+			}
+			err = view(file, name, f, formatter, *caller, false)
+			file.Close()
+			if err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logpy: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// stdoutMu keeps entries of files followed at the same time from interleaving
+var stdoutMu sync.Mutex
+
+// followAll follows every file in its own goroutine until one of them fails
+func followAll(names []string, f *filter, formatter *logpy.ConsoleFormatter, showCaller bool) error {
+	errs := make(chan error, len(names))
+	for _, name := range names {
+		file, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		fileFormatter := *formatter // view sets AddCaller per entry
+		go func() {
+			defer file.Close()
+			errs <- view(&followReader{r: file}, name, f, &fileFormatter, showCaller, true)
+		}()
+	}
+	return <-errs
+}
+
+// view prints the entries of r that pass f
+// When following, io.EOF only means no new data for now, so reading continues
+// The number of skipped lines is reported on stderr at the end of the input, or
+// when following, each time the reader catches up
+func view(r io.Reader, name string, f *filter, formatter *logpy.ConsoleFormatter, showCaller, follow bool) error {
+	dec := parse.NewDecoder(r)
+	skipped := 0
+	for {
+		entry, err := dec.Decode()
+		if err == io.EOF {
+			if skipped > 0 {
+				fmt.Fprintf(os.Stderr, "logpy: %s: skipped %d lines that are not log entries\n", name, skipped)
+				skipped = 0
+			}
+			if follow {
+				continue
+			}
+			return nil
+		}
+		if errors.Is(err, parse.ErrSyntax) {
+			// Not a log entry (e.g. a stack trace printed by something else)
+			skipped++
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !f.match(entry) {
+			continue
+		}
+		// Entries written without caller information have none to show
+		formatter.AddCaller = showCaller && entry.Caller.File != ""
+		data, err := formatter.Format(entry)
+		if err != nil {
+			return err
+		}
+		stdoutMu.Lock()
+		_, err = os.Stdout.Write(data)
+		stdoutMu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// newFilter builds a filter from the command-line flags
+func newFilter(level, since, until string, where []string) (*filter, error) {
+	f := &filter{}
+
+	var ok bool
+	if f.level, ok = levelFromString(level); !ok {
+		return nil, fmt.Errorf("invalid level %q", level)
+	}
+
+	var err error
+	if f.since, err = parseTime(since); err != nil {
+		return nil, fmt.Errorf("invalid --since: %w", err)
+	}
+	if f.until, err = parseTime(until); err != nil {
+		return nil, fmt.Errorf("invalid --until: %w", err)
+	}
+
+	for _, w := range where {
+		if key, value, found := strings.Cut(w, "!="); found {
+			f.where = append(f.where, condition{key: key, value: value, negate: true})
+			continue
+		}
+		key, value, _ := strings.Cut(w, "=")
+		f.where = append(f.where, condition{key: key, value: value})
+	}
+	return f, nil
+}
+
+// match reports whether entry passes every filter
+func (f *filter) match(entry logpy.Entry) bool {
+	if !entry.Level.AtLeast(f.level) {
+		return false
+	}
+	if !f.since.IsZero() && entry.Time.Before(f.since) {
+		return false
+	}
+	if !f.until.IsZero() && !entry.Time.Before(f.until) {
+		return false
+	}
+	for _, c := range f.where {
+		value, found := fieldValue(entry, c.key)
+		if (found && value == c.value) == c.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldValue returns the text of the field named key, searching event then context fields
+func fieldValue(entry logpy.Entry, key string) (string, bool) {
+	for _, fields := range [][]logpy.Field{entry.Fields, entry.ContextFields} {
+		for _, field := range fields {
+			if field.Key == key {
+				return fmt.Sprint(field.Value), true
+			}
+		}
+	}
+	return "", false
+}
+
+// levelFromString parses a level name, rejecting unknown names
+func levelFromString(s string) (logpy.Level, bool) {
+	level, err := logpy.ParseLevelStrict(s)
+	return level, err == nil
+}
+
+// parseTime accepts RFC 3339, "2006-01-02 15:04:05" in local time, a date, or a duration before now
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time %q", s)
+}
+
+// followReader waits for more data at end of file, like tail -f
+// Once per idle period at the end of a complete line it returns io.EOF, so the
+// decoder flushes an entry it is holding while looking ahead for continuation lines
+type followReader struct {
+	r        io.Reader
+	partial  bool // The last byte read did not end a line
+	reported bool // io.EOF was returned since the last data
+}
+
+func (f *followReader) Read(p []byte) (int, error) {
+	for {
+		n, err := f.r.Read(p)
+		if n > 0 {
+			f.partial = p[n-1] != '\n'
+			f.reported = false
+		}
+		if n > 0 || err != io.EOF {
+			if err == io.EOF {
+				err = nil
+			}
+			return n, err
+		}
+		if !f.partial && !f.reported {
+			f.reported = true
+			return 0, io.EOF
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
+
+// isTerminal checks if f is a terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -3,6 +3,7 @@ package logpy
 import (
 	"io"
 	"os"
+	"time"
 )
 
 // OutputType defines where logs should be written
@@ -20,14 +21,36 @@ type FormatType string
 const (
 	FormatJSON    FormatType = "json"
 	FormatConsole FormatType = "console"
+	FormatMsgpack FormatType = "msgpack" // Binary MessagePack, e.g. for fluentd
+	FormatCBOR    FormatType = "cbor"    // Binary CBOR
 )
 
 // RotationMode defines how log files should be rotated
 type RotationMode string
 
 const (
-	RotationSize  RotationMode = "size"  // Size-based rotation using lumberjack
-	RotationDaily RotationMode = "daily" // Daily rotation based on date
+	RotationSize   RotationMode = "size"   // Size-based rotation using lumberjack
+	RotationDaily  RotationMode = "daily"  // Daily rotation based on date
+	RotationHourly RotationMode = "hourly" // Hourly rotation based on date and hour
+)
+
+// ColorMode defines when colored output is used
+type ColorMode string
+
+const (
+	ColorAuto   ColorMode = "auto"   // Color only when writing to a terminal, honoring NO_COLOR and FORCE_COLOR
+	ColorAlways ColorMode = "always" // Always emit color codes
+	ColorNever  ColorMode = "never"  // Never emit color codes
+)
+
+// DuplicateKeyPolicy defines how repeated field keys within an entry are resolved
+// Context fields (from With()) are considered to come before event fields
+type DuplicateKeyPolicy string
+
+const (
+	DuplicateKeepLast    DuplicateKeyPolicy = "keep-last"    // Later fields override earlier ones (default)
+	DuplicateKeepFirst   DuplicateKeyPolicy = "keep-first"   // Earlier fields win, later duplicates are dropped
+	DuplicateSuffixIndex DuplicateKeyPolicy = "suffix-index" // Later duplicates are renamed key_1, key_2, ...
 )
 
 // Config holds the configuration for creating a logger
@@ -35,30 +58,75 @@ type Config struct {
 	// Level is the minimum log level to output
 	Level Level
 
-	// Format specifies the output format (json or console)
+	// Format specifies the output format (json, console, or a name passed to RegisterFormatter)
+	// Daily and hourly files use it too; size-rotated files are JSON unless FileFormat says otherwise
 	Format FormatType
 
-	// Output specifies where to write logs (stdout, stderr, or file)
+	// CustomFormatter, if set, formats entries for every destination instead of
+	// Format, ConsoleFormat and FileFormat; it must be safe for concurrent use
+	CustomFormatter Formatter
+
+	// Output specifies where to write logs (stdout, stderr, file, or a name passed to RegisterHandler)
 	Output OutputType
 
 	// OutputPath is the file path when Output is "file"
 	OutputPath string
 
+	// Console, File and Remote configure each destination separately with its own
+	// level, format and color. When any is set they replace Output, OutputPath,
+	// MultiOutput, Format, the per-leg overrides and the color settings; the other
+	// fields (timestamps, caller, limits, ...) still apply to every section
+	Console *ConsoleConfig
+	File    *FileConfig
+	Remote  *RemoteConfig
+
+	// SplitErrorOutput sends Debug/Info to stdout and Warn/Error to stderr
+	// Used when Output is "stdout" or "stderr"
+	SplitErrorOutput bool
+
+	// NonBlockingConsole writes stdout/stderr output from a background goroutine, so a
+	// stalled reader (e.g. a Docker log driver) cannot freeze the application. When
+	// ConsoleBufferSize lines (default 256) are queued, new lines are dropped and
+	// counted in the Dropped metric
+	NonBlockingConsole bool
+	ConsoleBufferSize  int
+
+	// SplitLevelFiles writes each level to its own file (debug.log, info.log, warn.log,
+	// error.log), each rotated separately. Used when Output is "file"
+	SplitLevelFiles bool
+
 	// UseColor enables colored output for console format
+	// Ignored when ColorMode is set
 	UseColor bool
 
+	// ColorMode selects auto, always or never coloring; empty falls back to UseColor
+	ColorMode ColorMode
+
 	// ColorConfig allows customization of level colors
 	ColorConfig ColorConfig
 
+	// Theme selects a predefined color scheme and overrides ColorConfig when set
+	Theme Theme
+
+	// MultilineValues renders multi-line string fields as indented blocks
+	// below the console log line instead of inline
+	MultilineValues bool
+
+	// PrettyJSON emits indented multi-line JSON (keys are colored when UseColor is set)
+	// Intended for local development with Format "json"
+	PrettyJSON bool
+
 	// AddCaller includes caller information (file and line number)
 	AddCaller bool
 
-	// RotationMode specifies the rotation strategy: "size" or "daily"
+	// RotationMode specifies the rotation strategy: "size", "daily" or "hourly"
 	// Only used when Output is "file"
 	RotationMode RotationMode
 
 	// File rotation settings (used when Output is "file")
-	// MaxSize is the maximum size in megabytes before rotation (for size-based rotation)
+	// MaxSize is the maximum size in megabytes before rotation
+	// With daily or hourly rotation, a period exceeding MaxSize rolls to numbered files
+	// Zero leaves daily/hourly files uncapped; size rotation then uses 100 MB
 	MaxSize int
 
 	// MaxBackups is the maximum number of old log files to retain (for size-based rotation)
@@ -67,11 +135,108 @@ type Config struct {
 	// MaxAge is the maximum number of days to retain old log files
 	MaxAge int
 
-	// Compress determines if rotated files should be compressed (for size-based rotation)
+	// MaxTotalSize is the maximum total size in megabytes of the log directory
+	// Oldest files are deleted first (daily/hourly rotation, 0 = unlimited)
+	MaxTotalSize int
+
+	// Compress determines if rotated files should be gzip compressed
 	Compress bool
 
+	// FileMode and DirMode set permissions for created log files and directories
+	// Zero values use 0644 and 0755
+	FileMode os.FileMode
+	DirMode  os.FileMode
+
+	// FileLocking makes daily/hourly files safe to share between processes
+	// by taking an advisory lock around each write
+	FileLocking bool
+
+	// FilenameTemplate customizes daily/hourly file names, e.g. "{prefix}-{hostname}-{date}.{ext}"
+	// Placeholders: {prefix}, {hostname}, {pid}, {date}, {index}, {ext}
+	FilenameTemplate string
+
+	// LatestLink maintains a "latest.log" link to the current file (daily/hourly rotation)
+	LatestLink bool
+
+	// Archiver uploads files after they are rotated out (daily/hourly rotation)
+	// See the archive package for S3, GCS and Azure Blob implementations
+	Archiver Archiver
+
+	// ArchiveDeleteLocal removes local files once they have been archived
+	ArchiveDeleteLocal bool
+
+	// SyncPolicy controls fsync of log files: never (default), always, error or interval
+	SyncPolicy SyncPolicy
+
+	// SyncInterval is the period used with SyncPolicy "interval" (default 1s)
+	SyncInterval time.Duration
+
 	// MultiOutput enables writing to both console and file
 	MultiOutput bool
+
+	// ConsoleLevel and FileLevel override Level for each leg when MultiOutput is enabled
+	// e.g. Debug on screen while files only store Info+ (nil uses Level)
+	ConsoleLevel *Level
+	FileLevel    *Level
+
+	// ConsoleFormat and FileFormat override the format of each leg when MultiOutput is enabled
+	// Empty uses console output for the console leg; files use Format (daily/hourly) or JSON (size)
+	ConsoleFormat FormatType
+	FileFormat    FormatType
+
+	// Location is the time zone used for timestamps and daily file dates
+	// nil means local time; use time.UTC for UTC output
+	Location *time.Location
+
+	// Clock supplies entry timestamps and daily/hourly file dates (nil uses time.Now)
+	// Tests can freeze or advance it for deterministic output
+	Clock Clock
+
+	// TimestampFormat is the time layout used for entry timestamps
+	// Empty uses the handler default ("2006-01-02 15:04:05" for console, ISO 8601 for JSON)
+	TimestampFormat string
+
+	// DurationEncoding controls how duration fields are rendered
+	// Empty means Go duration strings in console output and nanoseconds in JSON
+	DurationEncoding DurationEncoding
+
+	// TimestampEncoding and TimeEncoding write the entry timestamp and Time fields of
+	// JSON and binary output as epoch numbers, e.g. for ClickHouse or BigQuery ingestion
+	// Empty writes strings (TimestampFormat for the timestamp, RFC 3339 for fields)
+	TimestampEncoding TimestampEncoding
+	TimeEncoding      TimestampEncoding
+
+	// LevelEncoding and LevelLabels control how the level is written in JSON and binary
+	// output, e.g. lower-case names or numbers for case-sensitive ingestion pipelines
+	LevelEncoding LevelEncoding
+	LevelLabels   map[Level]string
+
+	// DuplicateKeys controls how repeated field keys are resolved (default keep-last)
+	DuplicateKeys DuplicateKeyPolicy
+
+	// Limits caps message, field and entry sizes, the number of fields and nesting
+	// depth; values past a limit are cut and marked. Zero means no limit
+	Limits Limits
+
+	// AddFingerprint adds a "fingerprint" field (hash of level and message) to every entry
+	// so log pipelines can group similar entries
+	AddFingerprint bool
+
+	// ErrorHandler is called when an entry cannot be written (e.g. disk full)
+	// nil prints the failure to stderr
+	ErrorHandler ErrorHandler
+
+	// DefaultFields are context fields on every logger created from this config
+	// (e.g. env, region, service), as if added with With()
+	DefaultFields []Field
+
+	// AddContainerID adds a "container_id" context field when running in a container,
+	// for container correlation by Datadog or Elastic agents
+	AddContainerID bool
+
+	// ContextExtractors take fields from the context passed to Logger.Ctx
+	// (e.g. otelbaggage.Extractor for OpenTelemetry baggage)
+	ContextExtractors []ContextExtractor
 }
 
 // DefaultConfig returns a configuration with sensible defaults
@@ -81,16 +246,14 @@ func DefaultConfig() Config {
 		Level:        InfoLevel,
 		Format:       FormatConsole,
 		Output:       OutputFile,
-		OutputPath:   "./logs",       // Just directory, no prefix
-		UseColor:     true,            // Colors in both console and file
+		OutputPath:   "./logs", // Just directory, no prefix
+		UseColor:     true,     // Colors in both console and file
 		ColorConfig:  DefaultColorConfig(),
 		AddCaller:    true,
-		RotationMode: RotationDaily,  // Daily rotation by default
-		MaxSize:      100,             // 100 MB (for size-based rotation)
-		MaxBackups:   3,               // Keep 3 old files (for size-based rotation)
-		MaxAge:       28,              // Keep for 28 days
-		Compress:     true,            // Compress old files (for size-based rotation)
-		MultiOutput:  true,            // Log to BOTH console and file
+		RotationMode: RotationDaily, // Daily rotation by default, one uncompressed file per day
+		MaxBackups:   3,             // Keep 3 old files (for size-based rotation)
+		MaxAge:       28,            // Keep for 28 days
+		MultiOutput:  true,          // Log to BOTH console and file
 	}
 }
 
@@ -101,12 +264,11 @@ func DevelopmentConfig() Config {
 		Format:      FormatConsole,
 		Output:      OutputStdout,
 		UseColor:    true,
+		ColorMode:   ColorAuto,
 		ColorConfig: DefaultColorConfig(),
 		AddCaller:   true,
-		MaxSize:     100,
 		MaxBackups:  3,
 		MaxAge:      28,
-		Compress:    true,
 		MultiOutput: false,
 	}
 }
@@ -129,12 +291,171 @@ func ProductionConfig() Config {
 	}
 }
 
-// isTerminal checks if stdout is a terminal
-func isTerminal() bool {
-	fileInfo, _ := os.Stdout.Stat()
+// Default timestamp layouts used by the built-in handlers
+const (
+	defaultConsoleTimestampFormat = "2006-01-02 15:04:05"
+	defaultJSONTimestampFormat    = "2006-01-02T15:04:05.000Z07:00" // ISO 8601
+)
+
+// timestampFormat returns the configured timestamp layout or def when unset
+func (c Config) timestampFormat(def string) string {
+	if c.TimestampFormat != "" {
+		return c.TimestampFormat
+	}
+	return def
+}
+
+// newConsoleFormatter builds a console formatter from the config
+func (c Config) newConsoleFormatter(useColor bool) *ConsoleFormatter {
+	return &ConsoleFormatter{
+		TimestampFormat:  c.timestampFormat(defaultConsoleTimestampFormat),
+		DurationEncoding: c.DurationEncoding,
+		AddCaller:        true,
+		UseColor:         useColor,
+		ColorConfig:      c.colorConfig(),
+		MultilineValues:  c.MultilineValues,
+	}
+}
+
+// colorConfig returns the theme colors when a theme is set, otherwise ColorConfig
+func (c Config) colorConfig() ColorConfig {
+	if c.Theme != "" {
+		return ThemeColorConfig(c.Theme)
+	}
+	return c.ColorConfig
+}
+
+// newJSONFormatter builds a JSON formatter from the config
+// Key colors are only applied to pretty-printed output
+func (c Config) newJSONFormatter(useColor bool) *JSONFormatter {
+	return &JSONFormatter{
+		TimestampFormat:   c.timestampFormat(defaultJSONTimestampFormat),
+		TimestampEncoding: c.TimestampEncoding,
+		TimeEncoding:      c.TimeEncoding,
+		DurationEncoding:  c.DurationEncoding,
+		LevelEncoding:     c.LevelEncoding,
+		LevelLabels:       c.LevelLabels,
+		AddCaller:         true,
+		Pretty:            c.PrettyJSON,
+		UseColor:          c.PrettyJSON && useColor,
+		KeyColor:          c.colorConfig().Key,
+	}
+}
+
+// newBinaryFormatter builds a MessagePack or CBOR formatter from the config
+func (c Config) newBinaryFormatter(format FormatType) *BinaryFormatter {
+	encoding := EncodingMsgpack
+	if format == FormatCBOR {
+		encoding = EncodingCBOR
+	}
+	return &BinaryFormatter{
+		Encoding:          encoding,
+		TimestampFormat:   c.timestampFormat(defaultJSONTimestampFormat),
+		TimestampEncoding: c.TimestampEncoding,
+		TimeEncoding:      c.TimeEncoding,
+		DurationEncoding:  c.DurationEncoding,
+		LevelEncoding:     c.LevelEncoding,
+		LevelLabels:       c.LevelLabels,
+		AddCaller:         true,
+	}
+}
+
+// useColor reports whether output to w should be colored
+// legacy is the value used when ColorMode is unset (the old UseColor behavior)
+func (c Config) useColor(w io.Writer, legacy bool) bool {
+	switch c.ColorMode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	case ColorAuto:
+		return autoColor(w)
+	default:
+		return legacy
+	}
+}
+
+// autoColor decides on coloring from the environment and the writer
+// NO_COLOR (https://no-color.org) disables colors, FORCE_COLOR enables them
+// regardless of the writer, otherwise colors are used only for terminals
+func autoColor(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if force, ok := os.LookupEnv("FORCE_COLOR"); ok {
+		return force != "0" && force != "false"
+	}
+	return isTerminal(w)
+}
+
+// isTerminal checks if w is a terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fileInfo, err := f.Stat()
+	if err != nil {
+		return false
+	}
 	return (fileInfo.Mode() & os.ModeCharDevice) != 0
 }
 
+// legLevel returns the level for a MultiOutput leg, falling back to Level
+func (c Config) legLevel(override *Level) Level {
+	if c.MultiOutput && override != nil {
+		return *override
+	}
+	return c.Level
+}
+
+// fileFormatter returns the formatter for log files; def is the rotation mode's default format
+func (c Config) fileFormatter(def FormatType, useColor bool) Formatter {
+	format := def
+	if c.MultiOutput && c.FileFormat != "" {
+		format = c.FileFormat
+	}
+	return c.formatter(format, useColor)
+}
+
+// formatter returns the formatter for format; useColor applies to console output
+func (c Config) formatter(format FormatType, useColor bool) Formatter {
+	if f, ok := c.pluginFormatter(format); ok {
+		return f
+	}
+	switch format {
+	case FormatJSON:
+		return c.newJSONFormatter(false)
+	case FormatMsgpack, FormatCBOR:
+		return c.newBinaryFormatter(format)
+	}
+	return c.newConsoleFormatter(useColor)
+}
+
+// customFormatter returns the formatter replacing the built-in formatter f for format
+func (c Config) customFormatter(format FormatType, f Formatter) Formatter {
+	if custom, ok := c.pluginFormatter(format); ok {
+		return custom
+	}
+	return f
+}
+
+// pluginFormatter returns CustomFormatter if set, otherwise the registered formatter named format
+func (c Config) pluginFormatter(format FormatType) (Formatter, bool) {
+	if c.CustomFormatter != nil {
+		return c.CustomFormatter, true
+	}
+	return c.registeredFormatter(format)
+}
+
+// streamWriter wraps a stdout/stderr writer in a NonBlockingWriter when NonBlockingConsole is set
+func (c Config) streamWriter(w io.Writer) io.Writer {
+	if !c.NonBlockingConsole || w == nil {
+		return w
+	}
+	return NewNonBlockingWriter(w, c.ConsoleBufferSize)
+}
+
 // getWriter returns the appropriate io.Writer based on config
 func (c Config) getWriter() io.Writer {
 	switch c.Output {
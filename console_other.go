@@ -0,0 +1,14 @@
+//go:build !windows
+
+package logpy
+
+import (
+	"io"
+	"os"
+)
+
+// consoleWriter prepares f for colored output
+// Terminals on non-Windows platforms understand ANSI codes natively
+func consoleWriter(f *os.File) io.Writer {
+	return f
+}
@@ -0,0 +1,43 @@
+//go:build windows
+
+package logpy
+
+import (
+	"io"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// enableVirtualTerminalProcessing is the console mode flag that makes the
+// Windows console interpret ANSI escape sequences
+const enableVirtualTerminalProcessing = 0x0004
+
+var (
+	kernel32           = syscall.NewLazyDLL("kernel32.dll")
+	procGetConsoleMode = kernel32.NewProc("GetConsoleMode")
+	procSetConsoleMode = kernel32.NewProc("SetConsoleMode")
+)
+
+// consoleWriter prepares f for colored output
+// Virtual terminal processing is enabled when f is a console; if that fails
+// (older cmd.exe / PowerShell hosts), ANSI codes are stripped instead
+func consoleWriter(f *os.File) io.Writer {
+	handle := f.Fd()
+
+	var mode uint32
+	ret, _, _ := procGetConsoleMode.Call(handle, uintptr(unsafe.Pointer(&mode)))
+	if ret == 0 {
+		// Not a console (redirected to a file or pipe), write as-is
+		return f
+	}
+	if mode&enableVirtualTerminalProcessing != 0 {
+		return f
+	}
+
+	ret, _, _ = procSetConsoleMode.Call(handle, uintptr(mode|enableVirtualTerminalProcessing))
+	if ret == 0 {
+		return &ansiStripWriter{w: f}
+	}
+	return f
+}
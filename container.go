@@ -0,0 +1,72 @@
+package logpy
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var (
+	// cgroupIDPattern matches a container ID at the end of a cgroup path, e.g.
+	// /docker/<id>, /kubepods/.../cri-containerd-<id>.scope or ECS /ecs/<task>/<id>-<n>
+	cgroupIDPattern = regexp.MustCompile(`(?:^|[/-])([0-9a-f]{64}|[0-9a-f]{32}-[0-9]+)(?:\.scope)?$`)
+	// mountIDPattern matches the container directory of Docker and Podman in mountinfo
+	mountIDPattern = regexp.MustCompile(`/containers/([0-9a-f]{64})/`)
+
+	containerIDOnce sync.Once
+	containerID     string
+)
+
+// ContainerID returns the ID of the container the process runs in, or "" outside
+// a container. It is read from /proc/self/cgroup, falling back to /proc/self/mountinfo
+// for cgroup v2 hosts, and cached after the first call
+func ContainerID() string {
+	containerIDOnce.Do(func() {
+		containerID = readContainerID("/proc/self/cgroup", parseCgroupContainerID)
+		if containerID == "" {
+			containerID = readContainerID("/proc/self/mountinfo", parseMountinfoContainerID)
+		}
+	})
+	return containerID
+}
+
+// readContainerID applies parse to the file at path, returning "" if it cannot be read
+func readContainerID(path string, parse func(io.Reader) string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	return parse(f)
+}
+
+// parseCgroupContainerID finds a container ID in /proc/self/cgroup lines
+// ("hierarchy-ID:controllers:path")
+func parseCgroupContainerID(r io.Reader) string {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if m := cgroupIDPattern.FindStringSubmatch(parts[2]); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// parseMountinfoContainerID finds a container ID in /proc/self/mountinfo, where
+// /etc/hostname and friends are mounted from the runtime's container directory
+func parseMountinfoContainerID(r io.Reader) string {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if m := mountIDPattern.FindStringSubmatch(scanner.Text()); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
@@ -1,14 +1,33 @@
 package logpy
 
 import (
+	"compress/gzip"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
 
-// DailyFileHandler is a handler that rotates log files daily
+// Default permissions for log files and directories
+const (
+	defaultFileMode os.FileMode = 0644
+	defaultDirMode  os.FileMode = 0755
+)
+
+// Date layouts used in rotated file names
+const (
+	dailyLayout  = "2006-01-02"    // One file per day (ISO 8601 date)
+	hourlyLayout = "2006-01-02-15" // One file per hour
+)
+
+// DailyFileHandler is a handler that rotates log files based on time
+// The rotation period is defined by the date layout: daily by default, hourly with NewHourlyFileHandler
 type DailyFileHandler struct {
 	*baseHandler
 	baseDir       string
@@ -20,6 +39,21 @@ type DailyFileHandler struct {
 	fileMutex     sync.Mutex
 	useColor      bool
 	colorConfig   ColorConfig
+	location      *time.Location
+	clock         Clock // Source of the current time (nil = time.Now)
+	maxSize       int64 // Maximum bytes per file before rolling to the next index (0 = unlimited)
+	currentSize   int64
+	currentIndex  int
+	maxTotalSize  int64 // Maximum bytes of logs kept in baseDir (0 = unlimited)
+	compress      bool  // Gzip files once they are rotated out
+	latestLink    bool  // Maintain a "latest.log" link to the current file
+	fileLocking   bool  // Serialize writes across processes with advisory locks
+
+	filenameTemplate string      // Custom file name layout, see SetFilenameTemplate
+	fileMode         os.FileMode // Permissions for newly created log files
+
+	archiver           Archiver // Receives files once they are rotated out
+	archiveDeleteLocal bool     // Remove the local copy after a successful upload
 }
 
 // NewDailyFileHandler creates a new daily rotating file handler
@@ -30,30 +64,53 @@ type DailyFileHandler struct {
 // useColor: whether to include color codes in the log files
 // colorConfig: color configuration for different log levels
 func NewDailyFileHandler(baseDir, filePrefix string, level Level, maxDaysToKeep int, useColor bool, colorConfig ColorConfig) (*DailyFileHandler, error) {
-	// Use default date layout (ISO 8601)
-	dateLayout := "2006-01-02"
+	formatter := &ConsoleFormatter{
+		TimestampFormat: defaultConsoleTimestampFormat,
+		AddCaller:       true,
+		UseColor:        useColor,
+		ColorConfig:     colorConfig,
+	}
 
-	// Create base directory if it doesn't exist
-	if err := os.MkdirAll(baseDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	h, err := newTimeFileHandler(baseDir, filePrefix, dailyLayout, level, maxDaysToKeep, formatter)
+	if err != nil {
+		return nil, err
 	}
+	h.useColor = useColor
+	h.colorConfig = colorConfig
+	return h, nil
+}
 
-	// Create formatter based on color preference
-	var formatter Formatter
-	if useColor {
-		formatter = &ConsoleFormatter{
-			TimestampFormat: "2006-01-02 15:04:05",
-			AddCaller:       true,
-			UseColor:        true,
-			ColorConfig:     colorConfig,
-		}
-	} else {
-		formatter = &ConsoleFormatter{
-			TimestampFormat: "2006-01-02 15:04:05",
-			AddCaller:       true,
-			UseColor:        false,
-			ColorConfig:     colorConfig,
-		}
+// NewDailyFileHandlerWithFormatter creates a daily rotating file handler that writes
+// entries formatted by f, e.g. a JSONFormatter or a custom Formatter
+func NewDailyFileHandlerWithFormatter(baseDir, filePrefix string, level Level, maxDaysToKeep int, f Formatter) (*DailyFileHandler, error) {
+	return newTimeFileHandler(baseDir, filePrefix, dailyLayout, level, maxDaysToKeep, f)
+}
+
+// NewHourlyFileHandler creates a file handler that rotates every hour
+// Files are named like "app-2025-11-06-15.log"; the parameters match NewDailyFileHandler
+func NewHourlyFileHandler(baseDir, filePrefix string, level Level, maxDaysToKeep int, useColor bool, colorConfig ColorConfig) (*DailyFileHandler, error) {
+	formatter := &ConsoleFormatter{
+		TimestampFormat: defaultConsoleTimestampFormat,
+		AddCaller:       true,
+		UseColor:        useColor,
+		ColorConfig:     colorConfig,
+	}
+
+	h, err := newTimeFileHandler(baseDir, filePrefix, hourlyLayout, level, maxDaysToKeep, formatter)
+	if err != nil {
+		return nil, err
+	}
+	h.useColor = useColor
+	h.colorConfig = colorConfig
+	return h, nil
+}
+
+// newTimeFileHandler creates a time rotating file handler with the given date layout and formatter
+// A new file is opened whenever the current time formatted with dateLayout changes
+func newTimeFileHandler(baseDir, filePrefix, dateLayout string, level Level, maxDaysToKeep int, formatter Formatter) (*DailyFileHandler, error) {
+	// Create base directory if it doesn't exist
+	if err := os.MkdirAll(baseDir, defaultDirMode); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
 	}
 
 	h := &DailyFileHandler{
@@ -61,8 +118,7 @@ func NewDailyFileHandler(baseDir, filePrefix string, level Level, maxDaysToKeep
 		filePrefix:    filePrefix,
 		dateLayout:    dateLayout,
 		maxDaysToKeep: maxDaysToKeep,
-		useColor:      useColor,
-		colorConfig:   colorConfig,
+		fileMode:      defaultFileMode,
 		baseHandler: &baseHandler{
 			level:     level,
 			formatter: formatter,
@@ -78,6 +134,111 @@ func NewDailyFileHandler(baseDir, filePrefix string, level Level, maxDaysToKeep
 	return h, nil
 }
 
+// SetLocation sets the time zone used to compute file dates (nil means local time)
+func (h *DailyFileHandler) SetLocation(loc *time.Location) {
+	h.fileMutex.Lock()
+	defer h.fileMutex.Unlock()
+	h.location = loc
+}
+
+// SetMaxSize caps each file at megabytes; once exceeded, the same period rolls over
+// to numbered files (app-2025-11-06.1.log, app-2025-11-06.2.log, ...). 0 disables the cap
+func (h *DailyFileHandler) SetMaxSize(megabytes int) {
+	h.fileMutex.Lock()
+	defer h.fileMutex.Unlock()
+	h.maxSize = int64(megabytes) * 1024 * 1024
+}
+
+// SetMaxTotalSize limits the total size of log files in the directory to megabytes
+// The oldest files are deleted first; it complements the age-based cleanup. 0 disables it
+func (h *DailyFileHandler) SetMaxTotalSize(megabytes int) {
+	h.fileMutex.Lock()
+	defer h.fileMutex.Unlock()
+	h.maxTotalSize = int64(megabytes) * 1024 * 1024
+}
+
+// SetCompress enables gzip compression of files once they are rotated out
+// Compressed files keep their name with a ".gz" suffix (app-2025-11-06.log.gz)
+func (h *DailyFileHandler) SetCompress(compress bool) {
+	h.fileMutex.Lock()
+	defer h.fileMutex.Unlock()
+	h.compress = compress
+}
+
+// SetArchiver registers an Archiver that receives each file after it is rotated
+// out (and compressed, if enabled). When deleteLocal is true the local copy is
+// removed once Archive succeeds
+func (h *DailyFileHandler) SetArchiver(archiver Archiver, deleteLocal bool) {
+	h.fileMutex.Lock()
+	defer h.fileMutex.Unlock()
+	h.archiver = archiver
+	h.archiveDeleteLocal = deleteLocal
+}
+
+// SetPermissions sets the mode for newly created log files and applies dirMode
+// to the log directory. A zero value leaves the corresponding default (0644 / 0755)
+func (h *DailyFileHandler) SetPermissions(fileMode, dirMode os.FileMode) error {
+	h.fileMutex.Lock()
+	defer h.fileMutex.Unlock()
+
+	if fileMode != 0 {
+		h.fileMode = fileMode
+	}
+	if dirMode != 0 {
+		if err := os.Chmod(h.baseDir, dirMode); err != nil {
+			return fmt.Errorf("failed to set log directory permissions: %w", err)
+		}
+	}
+	return nil
+}
+
+// SetFileLocking enables multi-process safe writing: every entry is appended
+// with a single O_APPEND write while holding an exclusive advisory lock (flock
+// on Unix, LockFileEx on Windows). Use it when several processes, such as
+// prefork workers, share one log file. Compression and archival of rotated
+// files are skipped in this mode since other processes may still be writing
+func (h *DailyFileHandler) SetFileLocking(enabled bool) {
+	h.fileMutex.Lock()
+	defer h.fileMutex.Unlock()
+	h.fileLocking = enabled
+}
+
+// SetFilenameTemplate sets a custom layout for file names instead of "prefix-DATE.log"
+// Supported placeholders: {prefix}, {hostname}, {pid}, {date}, {index} and {ext} ("log").
+// For example "{prefix}-{hostname}-{date}.{ext}" gives "app-web1-2025-11-06.log".
+// Without {index}, size rollover numbers are inserted before the extension
+func (h *DailyFileHandler) SetFilenameTemplate(template string) {
+	h.fileMutex.Lock()
+	defer h.fileMutex.Unlock()
+	h.filenameTemplate = template
+}
+
+// SetLatestLink enables maintenance of a link to the current file, so that
+// `tail -F logs/latest.log` keeps following the log across rotations
+// The link is named "latest.log", or "<prefix>-latest.log" when a prefix is set
+func (h *DailyFileHandler) SetLatestLink(enabled bool) {
+	h.fileMutex.Lock()
+	defer h.fileMutex.Unlock()
+	h.latestLink = enabled
+}
+
+// SetClock sets the clock used to pick file dates and expire old files (nil means time.Now)
+// Tests can use it to cross day or hour boundaries deterministically
+func (h *DailyFileHandler) SetClock(clock Clock) {
+	h.fileMutex.Lock()
+	defer h.fileMutex.Unlock()
+	h.clock = clock
+}
+
+// now returns the current time in the handler's configured location
+func (h *DailyFileHandler) now() time.Time {
+	now := clockNow(h.clock)
+	if h.location != nil {
+		now = now.In(h.location)
+	}
+	return now
+}
+
 // Write implements io.Writer interface with daily rotation
 func (h *DailyFileHandler) Write(p []byte) (n int, err error) {
 	h.fileMutex.Lock()
@@ -88,76 +249,337 @@ func (h *DailyFileHandler) Write(p []byte) (n int, err error) {
 		return 0, err
 	}
 
+	if h.fileLocking {
+		return h.writeLocked(p)
+	}
+
+	// Roll to the next numbered file if this write would exceed the size cap
+	if h.maxSize > 0 && h.currentSize > 0 && h.currentSize+int64(len(p)) > h.maxSize {
+		if err := h.openFile(h.currentDate, h.currentIndex+1); err != nil {
+			return 0, err
+		}
+	}
+	if err := h.writeHeader(h.currentFile); err != nil {
+		return 0, err
+	}
+
 	// Write to the current file
-	return h.currentFile.Write(p)
+	n, err = h.currentFile.Write(p)
+	h.currentSize += int64(n)
+	return n, err
+}
+
+// writeLocked writes p while holding an exclusive advisory lock on the file,
+// so entries from several processes sharing the file never interleave
+// The file size is re-read under the lock since other processes append too
+func (h *DailyFileHandler) writeLocked(p []byte) (n int, err error) {
+	for {
+		f := h.currentFile
+		if err := lockFile(f); err != nil {
+			return 0, fmt.Errorf("failed to lock log file: %w", err)
+		}
+
+		if info, err := f.Stat(); err == nil {
+			h.currentSize = info.Size()
+		}
+
+		if h.maxSize > 0 && h.currentSize > 0 && h.currentSize+int64(len(p)) > h.maxSize {
+			unlockFile(f)
+			if err := h.openFile(h.currentDate, h.currentIndex+1); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		if err := h.writeHeader(f); err != nil {
+			unlockFile(f)
+			return 0, err
+		}
+
+		n, err = f.Write(p)
+		h.currentSize += int64(n)
+		unlockFile(f)
+		return n, err
+	}
+}
+
+// writeHeader starts an empty file with the formatter's header, if it has one
+func (h *DailyFileHandler) writeHeader(f *os.File) error {
+	if h.currentSize > 0 {
+		return nil
+	}
+	header := formatterHeader(h.formatter)
+	if len(header) == 0 {
+		return nil
+	}
+	n, err := f.Write(header)
+	h.currentSize += int64(n)
+	return err
 }
 
 // rotateIfNeeded checks if the date has changed and opens a new file if needed
 func (h *DailyFileHandler) rotateIfNeeded() error {
-	today := time.Now().Format(h.dateLayout)
+	today := h.now().Format(h.dateLayout)
 
 	// If we're already on the correct date and file is open, no rotation needed
 	if h.currentDate == today && h.currentFile != nil {
 		return nil
 	}
 
+	// Continue with the latest numbered file for this period (e.g. after a restart)
+	// skipping files that were already compressed or are full
+	index := 0
+	for {
+		filename := h.buildFilename(today, index)
+		if _, err := os.Stat(filename + ".gz"); err == nil {
+			index++
+			continue
+		}
+		info, err := os.Stat(filename)
+		if err != nil || h.maxSize <= 0 || info.Size() < h.maxSize {
+			break
+		}
+		index++
+	}
+
+	return h.openFile(today, index)
+}
+
+// openFile closes the current file and opens the file for date and index
+func (h *DailyFileHandler) openFile(date string, index int) error {
 	// Close the current file if it exists
 	if h.currentFile != nil {
+		closed := h.currentFile.Name()
+		metrics.rotations.Add(1)
+		h.stats.rotations.Add(1)
 		if err := h.currentFile.Close(); err != nil {
 			// Log the error but continue with rotation
 			fmt.Fprintf(os.Stderr, "error closing log file: %v\n", err)
+		} else if (h.compress || h.archiver != nil) && !h.fileLocking {
+			// Compress and archive in background to avoid blocking writers
+			go finalizeFile(closed, h.compress, h.archiver, h.archiveDeleteLocal)
 		}
+		h.currentFile = nil
 	}
 
 	// Build the new filename
-	filename := h.buildFilename(today)
+	filename := h.buildFilename(date, index)
 
 	// Create the file (append mode, create if doesn't exist)
-	f, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, h.fileMode)
 	if err != nil {
 		return fmt.Errorf("failed to open log file %s: %w", filename, err)
 	}
 
+	// Track the existing size so appends count toward the cap
+	var size int64
+	if info, err := f.Stat(); err == nil {
+		size = info.Size()
+	}
+
 	h.currentFile = f
-	h.currentDate = today
+	h.currentDate = date
+	h.currentIndex = index
+	h.currentSize = size
+
+	if h.latestLink {
+		if err := h.updateLatestLink(filename); err != nil {
+			fmt.Fprintf(os.Stderr, "error updating latest log link: %v\n", err)
+		}
+	}
 
 	// Cleanup old files if configured
-	if h.maxDaysToKeep > 0 {
+	if h.maxDaysToKeep > 0 || h.maxTotalSize > 0 {
+		// The cutoff is taken here, under fileMutex, since the clock can change
+		var cutoff time.Time
+		if h.maxDaysToKeep > 0 {
+			cutoff = h.now().AddDate(0, 0, -h.maxDaysToKeep)
+		}
 		// Run cleanup in background to avoid blocking
-		go h.cleanupOldFiles()
+		go h.cleanupOldFiles(filename, h.namePattern(), cutoff, h.maxTotalSize)
 	}
-
 	return nil
 }
 
+// expandTemplate renders the filename template for date and index
+// An empty placeholder takes one neighbouring separator with it, so values
+// such as a hostname containing ".." or "--" are kept as they are
+func (h *DailyFileHandler) expandTemplate(date string, index int) string {
+	indexStr := ""
+	if index > 0 {
+		indexStr = strconv.Itoa(index)
+	}
+	return h.renderTemplate(date, strconv.Itoa(os.Getpid()), indexStr)
+}
+
+// renderTemplate fills the filename template with the given date, process ID and index
+func (h *DailyFileHandler) renderTemplate(date, pid, index string) string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	values := map[string]string{
+		"{prefix}":   h.filePrefix,
+		"{hostname}": hostname,
+		"{pid}":      pid,
+		"{date}":     date,
+		"{index}":    index,
+		"{ext}":      "log",
+	}
+
+	var name []byte
+	endsWithSep := false // name ends with a separator from the template
+	dropSep := false     // an empty placeholder at the start still needs a separator dropped
+	writeLiteral := func(lit string) {
+		if dropSep && lit != "" && isNameSeparator(lit[0]) {
+			lit = lit[1:]
+		}
+		if lit != "" {
+			dropSep = false
+			name = append(name, lit...)
+			endsWithSep = isNameSeparator(lit[len(lit)-1])
+		}
+	}
+
+	tmpl := h.filenameTemplate
+	for tmpl != "" {
+		open := strings.IndexByte(tmpl, '{')
+		closing := strings.IndexByte(tmpl[max(open, 0):], '}')
+		if open < 0 || closing < 0 {
+			writeLiteral(tmpl)
+			break
+		}
+		closing += open
+		writeLiteral(tmpl[:open])
+		placeholder := tmpl[open : closing+1]
+		tmpl = tmpl[closing+1:]
+
+		value, ok := values[placeholder]
+		switch {
+		case !ok:
+			writeLiteral(placeholder) // Unknown placeholders are kept as written
+		case value != "":
+			name = append(name, value...)
+			endsWithSep, dropSep = false, false
+		case endsWithSep:
+			name = name[:len(name)-1]
+			endsWithSep = false
+		case len(name) == 0:
+			dropSep = true
+		}
+	}
+	result := string(name)
+
+	// Without an {index} placeholder, insert the rollover number before the extension
+	if index != "" && !strings.Contains(h.filenameTemplate, "{index}") {
+		ext := filepath.Ext(result)
+		result = strings.TrimSuffix(result, ext) + "." + index + ext
+	}
+	return result
+}
+
+// isNameSeparator reports whether c separates placeholders in a filename template
+func isNameSeparator(c byte) bool {
+	return c == '-' || c == '_' || c == '.'
+}
+
+// latestLinkPath returns the path of the link to the current file
+func (h *DailyFileHandler) latestLinkPath() string {
+	if h.filePrefix != "" {
+		return filepath.Join(h.baseDir, h.filePrefix+"-latest.log")
+	}
+	return filepath.Join(h.baseDir, "latest.log")
+}
+
+// updateLatestLink points the latest link at target
+// A relative symlink is swapped in atomically; where symlinks are unavailable
+// (e.g. Windows without the required privilege) a hard link is used instead
+func (h *DailyFileHandler) updateLatestLink(target string) error {
+	link := h.latestLinkPath()
+	tmp := link + ".tmp"
+	os.Remove(tmp)
+
+	if err := os.Symlink(filepath.Base(target), tmp); err == nil {
+		return os.Rename(tmp, link)
+	}
+
+	// Fall back to a hard link, which also tracks appends to the current file
+	os.Remove(link)
+	return os.Link(target, link)
+}
+
 // buildFilename constructs the full path to the log file for a given date
-func (h *DailyFileHandler) buildFilename(date string) string {
-	var filename string
+// A non-zero index is inserted before the extension (app-2025-11-06.1.log)
+func (h *DailyFileHandler) buildFilename(date string, index int) string {
+	if h.filenameTemplate != "" {
+		return filepath.Join(h.baseDir, h.expandTemplate(date, index))
+	}
+
+	name := date
 	if h.filePrefix != "" {
-		filename = h.filePrefix + "-" + date + ".log"
-	} else {
-		filename = date + ".log"
+		name = h.filePrefix + "-" + date
 	}
-	return filepath.Join(h.baseDir, filename)
+	if index > 0 {
+		name += "." + strconv.Itoa(index)
+	}
+	return filepath.Join(h.baseDir, name+".log")
 }
 
-// cleanupOldFiles removes log files older than maxDaysToKeep days
-func (h *DailyFileHandler) cleanupOldFiles() {
-	cutoffDate := time.Now().AddDate(0, 0, -h.maxDaysToKeep)
+// namePattern matches the names of files this handler creates, for any date,
+// rollover index and process ID, including their compressed copies
+func (h *DailyFileHandler) namePattern() *regexp.Regexp {
+	// Every digit of the layout stands for one digit of the date
+	var b strings.Builder
+	for _, r := range h.dateLayout {
+		if r >= '0' && r <= '9' {
+			b.WriteString(`\d`)
+		} else {
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	date := b.String()
 
+	if h.filenameTemplate == "" {
+		prefix := ""
+		if h.filePrefix != "" {
+			prefix = regexp.QuoteMeta(h.filePrefix + "-")
+		}
+		return regexp.MustCompile(`^` + prefix + date + `(?:\.\d+)?\.log(?:\.gz)?$`)
+	}
+
+	// Render with markers for the values that vary between files, then swap in their patterns
+	markers := strings.NewReplacer("\x00", date, "\x01", `\d+`, "\x02", `\d+`)
+	plain := markers.Replace(regexp.QuoteMeta(h.renderTemplate("\x00", "\x01", "")))
+	indexed := markers.Replace(regexp.QuoteMeta(h.renderTemplate("\x00", "\x01", "\x02")))
+	return regexp.MustCompile(`^(?:` + plain + `|` + indexed + `)(?:\.gz)?$`)
+}
+
+// cleanupOldFiles removes log files last modified before cutoff, then removes the
+// oldest files until the directory holds at most maxTotalSize bytes of logs
+// Only files matching names are considered, so other logs in the directory and
+// the latest link are left alone
+// The current file is never removed; zero values disable either policy
+func (h *DailyFileHandler) cleanupOldFiles(current string, names *regexp.Regexp, cutoff time.Time, maxTotalSize int64) {
 	files, err := os.ReadDir(h.baseDir)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error reading log directory for cleanup: %v\n", err)
 		return
 	}
 
+	type logFile struct {
+		path string
+		info os.FileInfo
+	}
+	var remaining []logFile
+	var totalSize int64
+
 	for _, file := range files {
-		if file.IsDir() {
+		if file.IsDir() || file.Type()&os.ModeSymlink != 0 {
 			continue
 		}
 
-		// Only process .log files
-		if filepath.Ext(file.Name()) != ".log" {
+		// Only process this handler's files and their compressed copies
+		if !names.MatchString(file.Name()) {
 			continue
 		}
 
@@ -166,18 +588,75 @@ func (h *DailyFileHandler) cleanupOldFiles() {
 			continue
 		}
 
+		path := filepath.Join(h.baseDir, file.Name())
+
 		// Remove files modified before the cutoff date
-		if info.ModTime().Before(cutoffDate) {
-			path := filepath.Join(h.baseDir, file.Name())
+		if !cutoff.IsZero() && path != current && info.ModTime().Before(cutoff) {
 			if err := os.Remove(path); err != nil {
 				fmt.Fprintf(os.Stderr, "error removing old log file %s: %v\n", path, err)
 			}
+			continue
 		}
+
+		remaining = append(remaining, logFile{path: path, info: info})
+		totalSize += info.Size()
+	}
+
+	if maxTotalSize <= 0 || totalSize <= maxTotalSize {
+		return
 	}
+
+	// Remove the oldest files first until under the limit
+	sort.Slice(remaining, func(i, j int) bool {
+		return remaining[i].info.ModTime().Before(remaining[j].info.ModTime())
+	})
+	for _, file := range remaining {
+		if totalSize <= maxTotalSize {
+			break
+		}
+		if file.path == current {
+			continue
+		}
+		if err := os.Remove(file.path); err != nil {
+			fmt.Fprintf(os.Stderr, "error removing old log file %s: %v\n", file.path, err)
+			continue
+		}
+		totalSize -= file.info.Size()
+	}
+}
+
+// Stats returns the handler's counters and the current file, for health endpoints
+func (h *DailyFileHandler) Stats() FileStats {
+	stats := h.stats.snapshot()
+	h.fileMutex.Lock()
+	defer h.fileMutex.Unlock()
+	if h.currentFile != nil {
+		stats.Path = h.currentFile.Name()
+		stats.Size = h.currentSize
+	}
+	return stats
+}
+
+// Sync commits the current log file to stable storage
+func (h *DailyFileHandler) Sync() error {
+	h.fileMutex.Lock()
+	defer h.fileMutex.Unlock()
+
+	if h.currentFile != nil {
+		return h.currentFile.Sync()
+	}
+	return nil
+}
+
+// Flush implements the Flusher interface by syncing the current file to disk
+func (h *DailyFileHandler) Flush() error {
+	return h.Sync()
 }
 
 // Close closes the current log file
 func (h *DailyFileHandler) Close() error {
+	h.stopSync()
+
 	h.fileMutex.Lock()
 	defer h.fileMutex.Unlock()
 
@@ -188,3 +667,68 @@ func (h *DailyFileHandler) Close() error {
 	}
 	return nil
 }
+
+// finalizeFile post-processes a rotated file: optional gzip compression
+// followed by optional archival and removal of the local copy
+func finalizeFile(path string, compress bool, archiver Archiver, deleteLocal bool) {
+	if compress {
+		if err := gzipFile(path); err != nil {
+			fmt.Fprintf(os.Stderr, "error compressing log file %s: %v\n", path, err)
+		} else {
+			path += ".gz"
+		}
+	}
+
+	if archiver == nil {
+		return
+	}
+	if err := archiver.Archive(path); err != nil {
+		fmt.Fprintf(os.Stderr, "error archiving log file %s: %v\n", path, err)
+		return
+	}
+	if deleteLocal {
+		if err := os.Remove(path); err != nil {
+			fmt.Fprintf(os.Stderr, "error removing archived log file %s: %v\n", path, err)
+		}
+	}
+}
+
+// gzipFile writes a gzip copy of path next to it and removes the original
+func gzipFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	gzPath := path + ".gz"
+	dst, err := os.OpenFile(gzPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode())
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		gz.Close()
+		dst.Close()
+		os.Remove(gzPath)
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		os.Remove(gzPath)
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(gzPath)
+		return err
+	}
+
+	src.Close()
+	return os.Remove(path)
+}
@@ -0,0 +1,259 @@
+package logpy
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// manualClock is a Clock that only moves when advanced
+type manualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *manualClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// dirFiles returns the sorted names of the regular files in dir
+func dirFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestDailyFileHandlerRotation(t *testing.T) {
+	start := time.Date(2026, 3, 9, 22, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		layout    string
+		steps     []time.Duration // Clock advances between writes
+		wantFiles []string
+	}{
+		{"daily same day", dailyLayout, []time.Duration{time.Hour},
+			[]string{"app-2026-03-09.log"}},
+		{"daily crosses midnight", dailyLayout, []time.Duration{time.Hour, 2 * time.Hour},
+			[]string{"app-2026-03-09.log", "app-2026-03-10.log"}},
+		{"hourly", hourlyLayout, []time.Duration{10 * time.Minute, 30 * time.Minute, time.Hour},
+			[]string{"app-2026-03-09-22.log", "app-2026-03-09-23.log", "app-2026-03-10-00.log"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			h, err := newTimeFileHandler(dir, "app", tt.layout, InfoLevel, 0, &JSONFormatter{})
+			if err != nil {
+				t.Fatalf("newTimeFileHandler: %v", err)
+			}
+			defer h.Close()
+			clock := &manualClock{now: start}
+			h.SetClock(clock)
+
+			write := func() {
+				if _, err := h.Write([]byte("line\n")); err != nil {
+					t.Fatalf("Write: %v", err)
+				}
+			}
+			write()
+			for _, step := range tt.steps {
+				clock.advance(step)
+				write()
+			}
+
+			if got := dirFiles(t, dir); strings.Join(got, " ") != strings.Join(tt.wantFiles, " ") {
+				t.Errorf("files = %v, want %v", got, tt.wantFiles)
+			}
+		})
+	}
+}
+
+func TestDailyFileHandlerLocation(t *testing.T) {
+	dir := t.TempDir()
+	h, err := newTimeFileHandler(dir, "", dailyLayout, InfoLevel, 0, &JSONFormatter{})
+	if err != nil {
+		t.Fatalf("newTimeFileHandler: %v", err)
+	}
+	defer h.Close()
+	h.SetClock(ClockFunc(func() time.Time { return time.Date(2026, 3, 9, 23, 0, 0, 0, time.UTC) }))
+	h.SetLocation(time.FixedZone("UTC+2", 2*60*60))
+
+	if _, err := h.Write([]byte("line\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if got := dirFiles(t, dir); len(got) != 1 || got[0] != "2026-03-10.log" {
+		t.Errorf("files = %v, want [2026-03-10.log]", got)
+	}
+}
+
+func TestDailyFileHandlerSizeRollover(t *testing.T) {
+	dir := t.TempDir()
+	h, err := newTimeFileHandler(dir, "app", dailyLayout, InfoLevel, 0, &JSONFormatter{})
+	if err != nil {
+		t.Fatalf("newTimeFileHandler: %v", err)
+	}
+	defer h.Close()
+	h.SetClock(ClockFunc(func() time.Time { return time.Date(2026, 3, 9, 12, 0, 0, 0, time.UTC) }))
+	h.maxSize = 10
+
+	for i := 0; i < 3; i++ {
+		if _, err := h.Write([]byte("12345678\n")); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	want := []string{"app-2026-03-09.1.log", "app-2026-03-09.2.log", "app-2026-03-09.log"}
+	if got := dirFiles(t, dir); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+}
+
+func TestDailyFileHandlerFilenames(t *testing.T) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	pid := os.Getpid()
+
+	tests := []struct {
+		name     string
+		prefix   string
+		template string
+		index    int
+		want     string
+	}{
+		{"prefix", "app", "", 0, "app-2026-03-09.log"},
+		{"no prefix", "", "", 0, "2026-03-09.log"},
+		{"prefix with index", "app", "", 2, "app-2026-03-09.2.log"},
+		{"template", "app", "{prefix}-{hostname}-{date}.{ext}", 0, "app-" + hostname + "-2026-03-09.log"},
+		{"template with index", "app", "{prefix}_{date}_{index}.{ext}", 3, "app_2026-03-09_3.log"},
+		{"template index placeholder empty", "app", "{prefix}_{date}_{index}.{ext}", 0, "app_2026-03-09.log"},
+		{"template without index placeholder", "app", "{date}-{prefix}.{ext}", 1, "2026-03-09-app.1.log"},
+		{"template empty leading prefix", "", "{prefix}-{date}.{ext}", 0, "2026-03-09.log"},
+		{"template pid", "", "{date}.{pid}.{ext}", 0, "2026-03-09." + strconv.Itoa(pid) + ".log"},
+		{"template unknown placeholder", "app", "{prefix}-{nope}.{ext}", 0, "app-{nope}.log"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &DailyFileHandler{baseDir: "logs", filePrefix: tt.prefix, dateLayout: dailyLayout, filenameTemplate: tt.template}
+			got := h.buildFilename("2026-03-09", tt.index)
+			if want := filepath.Join("logs", tt.want); got != want {
+				t.Errorf("buildFilename = %q, want %q", got, want)
+			}
+			if !h.namePattern().MatchString(filepath.Base(got)) {
+				t.Errorf("namePattern does not match %q", filepath.Base(got))
+			}
+		})
+	}
+}
+
+func TestDailyFileHandlerNamePattern(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefix   string
+		layout   string
+		template string
+		file     string
+		want     bool
+	}{
+		{"daily", "app", dailyLayout, "", "app-2025-12-31.log", true},
+		{"daily rollover", "app", dailyLayout, "", "app-2025-12-31.4.log", true},
+		{"daily compressed", "app", dailyLayout, "", "app-2025-12-31.1.log.gz", true},
+		{"other prefix", "app", dailyLayout, "", "api-2025-12-31.log", false},
+		{"latest link", "app", dailyLayout, "", "app-latest.log", false},
+		{"hourly file for daily handler", "app", dailyLayout, "", "app-2025-12-31-04.log", false},
+		{"hourly", "app", hourlyLayout, "", "app-2025-12-31-04.log", true},
+		{"no prefix", "", dailyLayout, "", "2025-12-31.log", true},
+		{"no prefix other file", "", dailyLayout, "", "app-2025-12-31.log", false},
+		{"template other pid", "app", dailyLayout, "{prefix}-{date}-{pid}.{ext}", "app-2025-12-31-999.log", true},
+		{"template indexed", "app", dailyLayout, "{prefix}-{date}-{index}.{ext}", "app-2025-12-31-2.log.gz", true},
+		{"template other name", "app", dailyLayout, "{prefix}-{date}.{ext}", "app-2025-12-31.txt", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &DailyFileHandler{filePrefix: tt.prefix, dateLayout: tt.layout, filenameTemplate: tt.template}
+			if got := h.namePattern().MatchString(tt.file); got != tt.want {
+				t.Errorf("namePattern().MatchString(%q) = %v, want %v", tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDailyFileHandlerCleanup(t *testing.T) {
+	now := time.Date(2026, 3, 9, 12, 0, 0, 0, time.UTC)
+	// Files and their age in days; every file holds 10 bytes
+	files := map[string]int{
+		"app-2026-03-09.log":    0, // Current file
+		"app-2026-03-08.log":    1,
+		"app-2026-03-05.log.gz": 4,
+		"app-2026-03-01.log":    8,
+		"app-2026-03-01.1.log":  8,
+		"api-2026-03-01.log":    8, // Another handler's file
+		"notes.txt":             8,
+	}
+	tests := []struct {
+		name         string
+		maxDays      int
+		maxTotalSize int64
+		want         []string
+	}{
+		{"disabled", 0, 0, []string{
+			"api-2026-03-01.log", "app-2026-03-01.1.log", "app-2026-03-01.log",
+			"app-2026-03-05.log.gz", "app-2026-03-08.log", "app-2026-03-09.log", "notes.txt"}},
+		{"max days", 7, 0, []string{
+			"api-2026-03-01.log", "app-2026-03-05.log.gz", "app-2026-03-08.log", "app-2026-03-09.log", "notes.txt"}},
+		{"max total size", 0, 25, []string{
+			"api-2026-03-01.log", "app-2026-03-08.log", "app-2026-03-09.log", "notes.txt"}},
+		{"both", 3, 15, []string{
+			"api-2026-03-01.log", "app-2026-03-09.log", "notes.txt"}},
+		{"current file kept over size", 0, 1, []string{
+			"api-2026-03-01.log", "app-2026-03-09.log", "notes.txt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, age := range files {
+				path := filepath.Join(dir, name)
+				if err := os.WriteFile(path, []byte("0123456789"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				modTime := now.AddDate(0, 0, -age)
+				if err := os.Chtimes(path, modTime, modTime); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			h := &DailyFileHandler{baseDir: dir, filePrefix: "app", dateLayout: dailyLayout}
+			var cutoff time.Time
+			if tt.maxDays > 0 {
+				cutoff = now.AddDate(0, 0, -tt.maxDays)
+			}
+			h.cleanupOldFiles(filepath.Join(dir, "app-2026-03-09.log"), h.namePattern(), cutoff, tt.maxTotalSize)
+
+			if got := dirFiles(t, dir); strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("files = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
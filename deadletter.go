@@ -0,0 +1,349 @@
+package logpy
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	defaultDeadLetterRetries = 3
+	defaultDeadLetterBackoff = 100 * time.Millisecond
+)
+
+// DeadLetterHandler retries failed deliveries to an inner (typically remote) handler
+// and appends entries that still fail to a local NDJSON spool file, so no entries are
+// lost during outages. Replay re-sends the spooled entries later.
+// Retries block the caller, so wrap the handler in an AsyncHandler for hot paths
+type DeadLetterHandler struct {
+	inner   Handler
+	path    string
+	retries int
+	backoff time.Duration
+	mu      sync.Mutex // Serializes access to the spool file
+}
+
+// NewDeadLetterHandler creates a handler that retries inner 3 times with exponential
+// backoff starting at 100ms before appending the entry to the spool file at path
+func NewDeadLetterHandler(inner Handler, path string) *DeadLetterHandler {
+	return &DeadLetterHandler{
+		inner:   inner,
+		path:    path,
+		retries: defaultDeadLetterRetries,
+		backoff: defaultDeadLetterBackoff,
+	}
+}
+
+// SetRetries sets how many times a failed entry is retried and the initial backoff,
+// which doubles after each attempt. Zero retries spools on the first failure
+func (h *DeadLetterHandler) SetRetries(retries int, backoff time.Duration) {
+	if retries < 0 {
+		retries = 0
+	}
+	h.retries = retries
+	h.backoff = backoff
+}
+
+// Enabled implements the Handler interface
+func (h *DeadLetterHandler) Enabled(level Level) bool {
+	return h.inner.Enabled(level)
+}
+
+// Handle implements the Handler interface
+// It returns nil once an entry that could not be delivered is safely spooled
+func (h *DeadLetterHandler) Handle(entry Entry) error {
+	entry = entry.resolveLazy()
+
+	backoff := h.backoff
+	err := h.inner.Handle(entry)
+	for attempt := 0; err != nil && attempt < h.retries; attempt++ {
+		time.Sleep(backoff)
+		backoff *= 2
+		err = h.inner.Handle(entry)
+	}
+	if err == nil {
+		return nil
+	}
+
+	if spoolErr := h.spool(entry); spoolErr != nil {
+		return errors.Join(err, spoolErr)
+	}
+	return nil
+}
+
+// spool appends entry to the dead-letter file
+func (h *DeadLetterHandler) spool(entry Entry) error {
+	data, err := marshalDeadLetter(entry)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(h.path), defaultDirMode); err != nil {
+		return fmt.Errorf("failed to create dead-letter directory: %w", err)
+	}
+	f, err := os.OpenFile(h.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, defaultFileMode)
+	if err != nil {
+		return fmt.Errorf("failed to open dead-letter file: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write dead-letter file: %w", err)
+	}
+	return f.Close()
+}
+
+// Replay re-sends spooled entries to the inner handler, see ReplayDeadLetters
+func (h *DeadLetterHandler) Replay() (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return replayDeadLetters(h.path, h.inner)
+}
+
+// WithFields implements the Handler interface
+func (h *DeadLetterHandler) WithFields(fields []Field) Handler {
+	return h
+}
+
+// Flush flushes the inner handler
+func (h *DeadLetterHandler) Flush() error {
+	if f, ok := h.inner.(Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// Close closes the inner handler
+func (h *DeadLetterHandler) Close() error {
+	if c, ok := h.inner.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// ReplayDeadLetters sends the entries spooled at path to handler and returns how many
+// were delivered. Entries that fail again stay in the file; it is removed once empty.
+// A missing file is not an error
+func ReplayDeadLetters(path string, handler Handler) (int, error) {
+	return replayDeadLetters(path, handler)
+}
+
+// replayDeadLetters implements ReplayDeadLetters; callers serialize access to path
+func replayDeadLetters(path string, handler Handler) (int, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to open dead-letter file: %w", err)
+	}
+
+	var remaining bytes.Buffer
+	var errs []error
+	sent := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		entry, err := unmarshalDeadLetter(line)
+		if err == nil {
+			err = handler.Handle(entry)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			remaining.Write(line)
+			remaining.WriteByte('\n')
+			continue
+		}
+		sent++
+	}
+	f.Close()
+	if err := scanner.Err(); err != nil {
+		return sent, fmt.Errorf("failed to read dead-letter file: %w", err)
+	}
+
+	// Keep only what is still undelivered
+	if remaining.Len() == 0 {
+		if err := os.Remove(path); err != nil {
+			errs = append(errs, err)
+		}
+	} else if err := os.WriteFile(path, remaining.Bytes(), defaultFileMode); err != nil {
+		errs = append(errs, err)
+	}
+	return sent, errors.Join(errs...)
+}
+
+// deadLetter is the spool file record: one JSON object per line that keeps field
+// types so replayed entries match the originals
+type deadLetter struct {
+	Time    time.Time         `json:"time"`
+	Level   string            `json:"level"`
+	Message string            `json:"message"`
+	Caller  CallerInfo        `json:"caller"`
+	Fields  []deadLetterField `json:"fields,omitempty"`
+	Context []deadLetterField `json:"context,omitempty"`
+}
+
+// deadLetterField is a typed field in a spool record
+type deadLetterField struct {
+	Key   string          `json:"key"`
+	Type  FieldType       `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// marshalDeadLetter encodes entry as one NDJSON line
+func marshalDeadLetter(entry Entry) ([]byte, error) {
+	record := deadLetter{
+		Time:    entry.Time,
+		Level:   entry.Level.String(),
+		Message: entry.Message,
+		Caller:  entry.Caller,
+	}
+	var err error
+	if record.Fields, err = marshalDeadLetterFields(entry.Fields); err != nil {
+		return nil, err
+	}
+	if record.Context, err = marshalDeadLetterFields(entry.ContextFields); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// marshalDeadLetterFields encodes field values
+func marshalDeadLetterFields(fields []Field) ([]deadLetterField, error) {
+	out := make([]deadLetterField, 0, len(fields))
+	for _, field := range fields {
+		if field.Type == GroupType {
+			members, err := marshalDeadLetterFields(groupFields(field))
+			if err != nil {
+				return nil, err
+			}
+			value, err := json.Marshal(members)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, deadLetterField{Key: field.Key, Type: GroupType, Value: value})
+			continue
+		}
+		value, err := json.Marshal(field.Value)
+		if err != nil {
+			// Keep the entry even if one value cannot be encoded
+			value, _ = json.Marshal(fmt.Sprint(field.Value))
+			field.Type = StringType
+		}
+		out = append(out, deadLetterField{Key: field.Key, Type: field.Type, Value: value})
+	}
+	return out, nil
+}
+
+// unmarshalDeadLetter decodes a spool line back into an entry
+func unmarshalDeadLetter(line []byte) (Entry, error) {
+	var record deadLetter
+	if err := json.Unmarshal(line, &record); err != nil {
+		return Entry{}, fmt.Errorf("invalid dead-letter record: %w", err)
+	}
+	level, err := ParseLevel(record.Level)
+	if err != nil {
+		return Entry{}, err
+	}
+	return Entry{
+		Time:          record.Time,
+		Level:         level,
+		Message:       record.Message,
+		Caller:        record.Caller,
+		Fields:        unmarshalDeadLetterFields(record.Fields),
+		ContextFields: unmarshalDeadLetterFields(record.Context),
+	}, nil
+}
+
+// unmarshalDeadLetterFields restores field values with their original Go types
+// where the type is known; other values keep their generic JSON form
+func unmarshalDeadLetterFields(fields []deadLetterField) []Field {
+	out := make([]Field, 0, len(fields))
+	for _, f := range fields {
+		var value interface{}
+		var err error
+		switch f.Type {
+		case IntType:
+			value, err = decodeAs[int](f.Value)
+		case Int64Type:
+			value, err = decodeAs[int64](f.Value)
+		case Int32Type:
+			value, err = decodeAs[int32](f.Value)
+		case Int16Type:
+			value, err = decodeAs[int16](f.Value)
+		case Int8Type:
+			value, err = decodeAs[int8](f.Value)
+		case UintType:
+			value, err = decodeAs[uint](f.Value)
+		case Uint32Type:
+			value, err = decodeAs[uint32](f.Value)
+		case Uint64Type:
+			value, err = decodeAs[uint64](f.Value)
+		case Float64Type:
+			value, err = decodeAs[float64](f.Value)
+		case Float32Type:
+			value, err = decodeAs[float32](f.Value)
+		case BoolType:
+			value, err = decodeAs[bool](f.Value)
+		case TimeType:
+			value, err = decodeAs[time.Time](f.Value)
+		case DurationType:
+			value, err = decodeAs[time.Duration](f.Value)
+		case StringsType, ErrorsType:
+			value, err = decodeAs[[]string](f.Value)
+		case IntsType:
+			value, err = decodeAs[[]int](f.Value)
+		case Float64sType:
+			value, err = decodeAs[[]float64](f.Value)
+		case BoolsType:
+			value, err = decodeAs[[]bool](f.Value)
+		case DurationsType:
+			value, err = decodeAs[[]time.Duration](f.Value)
+		case ErrorType:
+			// A nil error is stored as null
+			var s *string
+			if err = json.Unmarshal(f.Value, &s); err == nil && s != nil {
+				value = *s
+			}
+		case GroupType:
+			var members []deadLetterField
+			if err = json.Unmarshal(f.Value, &members); err == nil {
+				value = unmarshalDeadLetterFields(members)
+			}
+		case StringType, IPAddrType, IPPrefixType, MACAddrType:
+			value, err = decodeAs[string](f.Value)
+		default:
+			err = json.Unmarshal(f.Value, &value)
+		}
+		if err != nil {
+			// Fall back to the raw JSON text rather than dropping the field
+			out = append(out, Field{Key: f.Key, Type: StringType, Value: string(f.Value)})
+			continue
+		}
+		out = append(out, Field{Key: f.Key, Type: f.Type, Value: value})
+	}
+	return out
+}
+
+// decodeAs decodes raw JSON into a value of type T
+func decodeAs[T any](raw json.RawMessage) (T, error) {
+	var v T
+	err := json.Unmarshal(raw, &v)
+	return v, err
+}
@@ -0,0 +1,186 @@
+package logpy
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDeadLetterHandlerRetries(t *testing.T) {
+	tests := []struct {
+		name        string
+		fail        bool
+		retries     int
+		wantCalls   int
+		wantSpooled int
+	}{
+		{"delivered", false, 2, 1, 0},
+		{"no retries", true, 0, 1, 1},
+		{"retries exhausted", true, 2, 3, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "spool", "dead.ndjson")
+			inner := &flakyHandler{fail: tt.fail}
+			h := NewDeadLetterHandler(inner, path)
+			h.SetRetries(tt.retries, time.Millisecond)
+
+			if err := h.Handle(Entry{Level: InfoLevel, Message: "lost"}); err != nil {
+				t.Fatalf("Handle = %v, want nil", err)
+			}
+			if got := inner.callCount(); got != tt.wantCalls {
+				t.Errorf("inner calls = %d, want %d", got, tt.wantCalls)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				t.Fatalf("ReadFile: %v", err)
+			}
+			if got := bytes.Count(data, []byte("\n")); got != tt.wantSpooled {
+				t.Errorf("spooled entries = %d, want %d", got, tt.wantSpooled)
+			}
+		})
+	}
+}
+
+func TestDeadLetterHandlerSpoolError(t *testing.T) {
+	dir := t.TempDir()
+	// A file where the spool directory should be makes spooling fail
+	blocker := filepath.Join(dir, "blocker")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	h := NewDeadLetterHandler(&flakyHandler{fail: true}, filepath.Join(blocker, "dead.ndjson"))
+	h.SetRetries(0, 0)
+
+	if err := h.Handle(Entry{Level: InfoLevel}); !errors.Is(err, errTestBackend) {
+		t.Fatalf("Handle = %v, want the delivery and spool errors", err)
+	}
+}
+
+func TestDeadLetterRoundTrip(t *testing.T) {
+	when := time.Date(2026, 3, 9, 12, 30, 0, 0, time.UTC)
+	entry := Entry{
+		Time:    when,
+		Level:   WarnLevel,
+		Message: "upload failed",
+		Caller:  CallerInfo{File: "main.go", Line: 42, Function: "main.run"},
+		Fields: []Field{
+			String("user", "ana"),
+			Int("attempt", 3),
+			Int64("bytes", 1<<40),
+			Uint64("id", 1<<63),
+			Float64("ratio", 0.25),
+			Bool("retry", true),
+			Time("started", when.Add(-time.Minute)),
+			Duration("took", 1500*time.Millisecond),
+			Strs("tags", []string{"a", "b"}),
+			Ints("codes", []int{500, 503}),
+			Durs("waits", []time.Duration{time.Second, 2 * time.Second}),
+			Error(errors.New("timeout")),
+			Group("http", String("method", "PUT"), Int("status", 503)),
+		},
+		ContextFields: []Field{String("service", "api")},
+	}
+
+	data, err := marshalDeadLetter(entry)
+	if err != nil {
+		t.Fatalf("marshalDeadLetter: %v", err)
+	}
+	got, err := unmarshalDeadLetter(bytes.TrimSpace(data))
+	if err != nil {
+		t.Fatalf("unmarshalDeadLetter: %v", err)
+	}
+
+	if !got.Time.Equal(entry.Time) || got.Level != entry.Level || got.Message != entry.Message || got.Caller != entry.Caller {
+		t.Errorf("entry = %v %s %q %+v, want %v %s %q %+v",
+			got.Time, got.Level, got.Message, got.Caller, entry.Time, entry.Level, entry.Message, entry.Caller)
+	}
+	for _, pair := range []struct{ got, want []Field }{{got.Fields, entry.Fields}, {got.ContextFields, entry.ContextFields}} {
+		if len(pair.got) != len(pair.want) {
+			t.Fatalf("fields = %v, want %v", pair.got, pair.want)
+		}
+		for i, want := range pair.want {
+			field := pair.got[i]
+			if field.Key != want.Key || field.Type != want.Type {
+				t.Errorf("field %d = %s (type %d), want %s (type %d)", i, field.Key, field.Type, want.Key, want.Type)
+				continue
+			}
+			if want.Type == TimeType {
+				if !field.Value.(time.Time).Equal(want.Value.(time.Time)) {
+					t.Errorf("%s = %v, want %v", want.Key, field.Value, want.Value)
+				}
+				continue
+			}
+			if !reflect.DeepEqual(field.Value, want.Value) {
+				t.Errorf("%s = %#v, want %#v", want.Key, field.Value, want.Value)
+			}
+		}
+	}
+}
+
+func TestDeadLetterHandlerReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead.ndjson")
+	inner := &flakyHandler{fail: true}
+	h := NewDeadLetterHandler(inner, path)
+	h.SetRetries(0, 0)
+	for _, msg := range []string{"one", "two"} {
+		if err := h.Handle(Entry{Level: InfoLevel, Message: msg}); err != nil {
+			t.Fatalf("Handle: %v", err)
+		}
+	}
+
+	// Entries that still fail stay in the spool file
+	sent, err := h.Replay()
+	if sent != 0 || !errors.Is(err, errTestBackend) {
+		t.Fatalf("Replay while failing = %d, %v, want 0 and the delivery error", sent, err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("spool file after failed replay: %v", err)
+	}
+
+	inner.setFail(false)
+	sent, err = h.Replay()
+	if sent != 2 || err != nil {
+		t.Fatalf("Replay = %d, %v, want 2, nil", sent, err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("spool file after replay: %v, want it removed", err)
+	}
+
+	// Nothing left to replay
+	if sent, err := ReplayDeadLetters(path, inner); sent != 0 || err != nil {
+		t.Fatalf("ReplayDeadLetters on a missing file = %d, %v, want 0, nil", sent, err)
+	}
+}
+
+func TestReplayDeadLettersKeepsInvalidLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead.ndjson")
+	good, err := marshalDeadLetter(Entry{Level: ErrorLevel, Message: "kept"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bad := []byte(`{"level":"info","message":` + "\n")
+	if err := os.WriteFile(path, append(append([]byte(nil), bad...), good...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := &recordingHandler{}
+	sent, err := ReplayDeadLetters(path, handler)
+	if sent != 1 || err == nil {
+		t.Fatalf("ReplayDeadLetters = %d, %v, want 1 and an error for the invalid line", sent, err)
+	}
+	if got := handler.last(t).Message; got != "kept" {
+		t.Errorf("replayed message = %q, want %q", got, "kept")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if !bytes.Equal(data, bad) {
+		t.Errorf("spool file = %q, want only the invalid line %q", data, bad)
+	}
+}
@@ -0,0 +1,178 @@
+package logpy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// DedupHandler suppresses identical entries (same level, message and fields) seen
+// again within a time window. When the window ends, a summary entry reports how
+// many duplicates were suppressed
+type DedupHandler struct {
+	inner       Handler
+	window      time.Duration
+	fingerprint FingerprintFunc
+	clock       Clock // Times windows and summaries (nil = time.Now)
+
+	mu      sync.Mutex
+	entries map[string]*dedupState
+	stop    chan struct{}
+	stopped sync.Once
+}
+
+// dedupState tracks one distinct entry within its window
+type dedupState struct {
+	entry      Entry     // First occurrence, used for the summary
+	first      time.Time // Start of the window
+	suppressed int
+}
+
+// NewDedupHandler creates a handler that passes the first of identical entries to
+// inner and suppresses the rest until window has elapsed (default 1 minute)
+func NewDedupHandler(inner Handler, window time.Duration) *DedupHandler {
+	if window <= 0 {
+		window = time.Minute
+	}
+
+	h := &DedupHandler{
+		inner:       inner,
+		window:      window,
+		fingerprint: fingerprintAllFields,
+		entries:     make(map[string]*dedupState),
+		stop:        make(chan struct{}),
+	}
+	go h.run()
+	return h
+}
+
+// run emits summaries for windows that have ended
+func (h *DedupHandler) run() {
+	ticker := time.NewTicker(h.window)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			h.expire(false)
+		case <-h.stop:
+			return
+		}
+	}
+}
+
+// SetFingerprint changes which entries count as identical (default: level, message and all fields)
+// It should be called before logging
+func (h *DedupHandler) SetFingerprint(fn FingerprintFunc) {
+	if fn == nil {
+		fn = fingerprintAllFields
+	}
+	h.fingerprint = fn
+}
+
+// SetClock sets the clock that times windows and summaries (nil means time.Now)
+// Use the logger's Config.Clock so windows follow a test clock
+func (h *DedupHandler) SetClock(clock Clock) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.clock = clock
+}
+
+// Enabled implements the Handler interface
+func (h *DedupHandler) Enabled(level Level) bool {
+	return h.inner.Enabled(level)
+}
+
+// Handle implements the Handler interface
+func (h *DedupHandler) Handle(entry Entry) error {
+	entry = entry.resolveLazy()
+	key := h.fingerprint(entry)
+
+	h.mu.Lock()
+	now := clockNow(h.clock)
+	state, ok := h.entries[key]
+	if ok && now.Sub(state.first) < h.window {
+		state.suppressed++
+		h.mu.Unlock()
+		return nil
+	}
+	h.entries[key] = &dedupState{entry: entry, first: now}
+	h.mu.Unlock()
+
+	// A previous window for this entry ended before the ticker noticed
+	if ok && state.suppressed > 0 {
+		if err := h.inner.Handle(h.summary(state, now)); err != nil {
+			return err
+		}
+	}
+	return h.inner.Handle(entry)
+}
+
+// expire emits summaries for, and forgets, entries whose window has ended, or all
+// entries when all is set
+func (h *DedupHandler) expire(all bool) {
+	h.mu.Lock()
+	now := clockNow(h.clock)
+	var summaries []Entry
+	for key, state := range h.entries {
+		if !all && now.Sub(state.first) < h.window {
+			continue
+		}
+		if state.suppressed > 0 {
+			summaries = append(summaries, h.summary(state, now))
+		}
+		delete(h.entries, key)
+	}
+	h.mu.Unlock()
+
+	for _, summary := range summaries {
+		if err := h.inner.Handle(summary); err != nil {
+			reportError(nil, err, summary)
+		}
+	}
+}
+
+// summary builds the entry reporting suppressed duplicates of state, timestamped now
+func (h *DedupHandler) summary(state *dedupState, now time.Time) Entry {
+	entry := state.entry
+	entry.Time = now
+	entry.Message = fmt.Sprintf("%s (repeated %d times in last %s)", entry.Message, state.suppressed, h.window)
+	entry.Fields = append(append([]Field(nil), entry.Fields...), Int("repeated", state.suppressed))
+	return entry
+}
+
+// WithFields implements the Handler interface
+func (h *DedupHandler) WithFields(fields []Field) Handler {
+	return h
+}
+
+// Flush emits pending summaries and flushes the inner handler
+func (h *DedupHandler) Flush() error {
+	h.expire(true)
+	if f, ok := h.inner.(Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// Drain implements the Drainer interface for an asynchronous inner handler
+func (h *DedupHandler) Drain(ctx context.Context) (int, error) {
+	h.stopped.Do(func() { close(h.stop) })
+	h.expire(true)
+	if d, ok := h.inner.(Drainer); ok {
+		return d.Drain(ctx)
+	}
+	return 0, nil
+}
+
+// Close emits pending summaries, stops the background goroutine and closes the inner handler
+func (h *DedupHandler) Close() error {
+	h.stopped.Do(func() { close(h.stop) })
+	err := h.Flush()
+	if c, ok := h.inner.(io.Closer); ok {
+		err = errors.Join(err, c.Close())
+	}
+	return err
+}
@@ -1,6 +1,10 @@
 package logpy
 
-import "time"
+import (
+	"net"
+	"net/netip"
+	"time"
+)
 
 // Entry represents a complete log entry
 type Entry struct {
@@ -12,24 +16,46 @@ type Entry struct {
 	Caller        CallerInfo
 }
 
+// resolveLazy returns a copy of the entry with all lazy fields evaluated
+func (e Entry) resolveLazy() Entry {
+	e.Fields = resolveLazyFields(e.Fields)
+	e.ContextFields = resolveLazyFields(e.ContextFields)
+	return e
+}
+
 // Event is a fluent API builder for creating log entries
 // It allows chaining methods to build up a log entry before sending it
 type Event struct {
-	logger    *Logger
-	level     Level
-	fields    []Field
-	timestamp time.Time
-	enabled   bool
+	logger     *Logger
+	level      Level
+	fields     []Field
+	timestamp  time.Time
+	enabled    bool
+	callerSkip int         // Extra frames to skip when capturing the caller
+	noCaller   bool        // Do not capture the caller
+	caller     *CallerInfo // Reported instead of capturing the caller
 }
 
+// disabledEvent is shared by all events that are not logged, so they cost no allocation
+// Event methods must not modify a disabled event
+var disabledEvent = &Event{}
+
 // newEvent creates a new event for the given logger and level
 func newEvent(logger *Logger, level Level) *Event {
-	enabled := logger.handler.Enabled(level)
+	enabled := !logger.disabled && !logger.isShutdown() &&
+		(logger.handler.Enabled(level) || (logger.state != nil && logger.state.subscribers.wants(level)))
+	if !enabled {
+		return disabledEvent
+	}
+	timestamp := clockNow(logger.clock)
+	if logger.location != nil {
+		timestamp = timestamp.In(logger.location)
+	}
 	return &Event{
 		logger:    logger,
 		level:     level,
-		timestamp: time.Now(),
-		enabled:   enabled,
+		timestamp: timestamp,
+		enabled:   true,
 	}
 }
 
@@ -60,6 +86,69 @@ func (e *Event) Int64(key string, val int64) *Event {
 	return e
 }
 
+// Int32 adds an int32 field to the event
+func (e *Event) Int32(key string, val int32) *Event {
+	if !e.enabled {
+		return e
+	}
+	e.fields = append(e.fields, Int32(key, val))
+	return e
+}
+
+// Int16 adds an int16 field to the event
+func (e *Event) Int16(key string, val int16) *Event {
+	if !e.enabled {
+		return e
+	}
+	e.fields = append(e.fields, Int16(key, val))
+	return e
+}
+
+// Int8 adds an int8 field to the event
+func (e *Event) Int8(key string, val int8) *Event {
+	if !e.enabled {
+		return e
+	}
+	e.fields = append(e.fields, Int8(key, val))
+	return e
+}
+
+// Uint adds a uint field to the event
+func (e *Event) Uint(key string, val uint) *Event {
+	if !e.enabled {
+		return e
+	}
+	e.fields = append(e.fields, Uint(key, val))
+	return e
+}
+
+// Uint32 adds a uint32 field to the event
+func (e *Event) Uint32(key string, val uint32) *Event {
+	if !e.enabled {
+		return e
+	}
+	e.fields = append(e.fields, Uint32(key, val))
+	return e
+}
+
+// Uint64 adds a uint64 field to the event
+func (e *Event) Uint64(key string, val uint64) *Event {
+	if !e.enabled {
+		return e
+	}
+	e.fields = append(e.fields, Uint64(key, val))
+	return e
+}
+
+// Float32 adds a float32 field to the event
+func (e *Event) Float32(key string, val float32) *Event {
+	if !e.enabled {
+		return e
+	}
+	e.fields = append(e.fields, Float32(key, val))
+	return e
+}
+
 // Float64 adds a float64 field to the event
 func (e *Event) Float64(key string, val float64) *Event {
 	if !e.enabled {
@@ -96,6 +185,78 @@ func (e *Event) Dur(key string, val time.Duration) *Event {
 	return e
 }
 
+// Strs adds a string slice field to the event
+func (e *Event) Strs(key string, vals []string) *Event {
+	if !e.enabled {
+		return e
+	}
+	e.fields = append(e.fields, Strs(key, vals))
+	return e
+}
+
+// Ints adds an int slice field to the event
+func (e *Event) Ints(key string, vals []int) *Event {
+	if !e.enabled {
+		return e
+	}
+	e.fields = append(e.fields, Ints(key, vals))
+	return e
+}
+
+// Floats adds a float64 slice field to the event
+func (e *Event) Floats(key string, vals []float64) *Event {
+	if !e.enabled {
+		return e
+	}
+	e.fields = append(e.fields, Floats(key, vals))
+	return e
+}
+
+// Bools adds a boolean slice field to the event
+func (e *Event) Bools(key string, vals []bool) *Event {
+	if !e.enabled {
+		return e
+	}
+	e.fields = append(e.fields, Bools(key, vals))
+	return e
+}
+
+// Durs adds a duration slice field to the event
+func (e *Event) Durs(key string, vals []time.Duration) *Event {
+	if !e.enabled {
+		return e
+	}
+	e.fields = append(e.fields, Durs(key, vals))
+	return e
+}
+
+// IPAddr adds an IP address field to the event
+func (e *Event) IPAddr(key string, val netip.Addr) *Event {
+	if !e.enabled {
+		return e
+	}
+	e.fields = append(e.fields, IPAddr(key, val))
+	return e
+}
+
+// IPPrefix adds an IP prefix (CIDR) field to the event
+func (e *Event) IPPrefix(key string, val netip.Prefix) *Event {
+	if !e.enabled {
+		return e
+	}
+	e.fields = append(e.fields, IPPrefix(key, val))
+	return e
+}
+
+// MACAddr adds a hardware address field to the event
+func (e *Event) MACAddr(key string, val net.HardwareAddr) *Event {
+	if !e.enabled {
+		return e
+	}
+	e.fields = append(e.fields, MACAddr(key, val))
+	return e
+}
+
 // Err adds an error field to the event
 func (e *Event) Err(err error) *Event {
 	if !e.enabled {
@@ -105,6 +266,15 @@ func (e *Event) Err(err error) *Event {
 	return e
 }
 
+// Errs adds a field with the messages of multiple errors to the event
+func (e *Event) Errs(key string, errs []error) *Event {
+	if !e.enabled {
+		return e
+	}
+	e.fields = append(e.fields, Errs(key, errs))
+	return e
+}
+
 // Any adds a field with any value type to the event
 func (e *Event) Any(key string, val interface{}) *Event {
 	if !e.enabled {
@@ -114,6 +284,15 @@ func (e *Event) Any(key string, val interface{}) *Event {
 	return e
 }
 
+// Func adds a field whose value is computed by fn only if the entry is handled
+func (e *Event) Func(key string, fn func() interface{}) *Event {
+	if !e.enabled {
+		return e
+	}
+	e.fields = append(e.fields, Lazy(key, fn))
+	return e
+}
+
 // Fields adds multiple fields to the event
 func (e *Event) Fields(fields ...Field) *Event {
 	if !e.enabled {
@@ -123,24 +302,168 @@ func (e *Event) Fields(fields ...Field) *Event {
 	return e
 }
 
+// FieldsMap adds the entries of m as typed fields, sorted by key
+func (e *Event) FieldsMap(m map[string]interface{}) *Event {
+	if !e.enabled {
+		return e
+	}
+	e.fields = append(e.fields, FieldsFromMap(m)...)
+	return e
+}
+
+// Ts sets the entry timestamp, e.g. the original time of an entry replayed from a
+// queue or imported from another system, instead of the time the event was created
+func (e *Event) Ts(t time.Time) *Event {
+	if !e.enabled {
+		return e
+	}
+	if e.logger.location != nil {
+		t = t.In(e.logger.location)
+	}
+	e.timestamp = t
+	return e
+}
+
+// CallerSkip reports the caller skip frames further up the stack, for helpers that
+// log on behalf of their callers: CallerSkip(1) reports the helper's caller
+func (e *Event) CallerSkip(skip int) *Event {
+	if !e.enabled {
+		return e
+	}
+	e.callerSkip += skip
+	return e
+}
+
+// NoCaller skips capturing the caller, saving the runtime.Caller lookup on hot paths
+// Formatters omit the caller of such entries
+func (e *Event) NoCaller() *Event {
+	if !e.enabled {
+		return e
+	}
+	e.noCaller = true
+	return e
+}
+
+// Caller reports info as the caller instead of capturing it, for entries forwarded
+// from another logging library that already knows where they were logged
+func (e *Event) Caller(info CallerInfo) *Event {
+	if !e.enabled {
+		return e
+	}
+	e.caller = &info
+	return e
+}
+
+// If discards the event unless cond is true, keeping the chain fluent
+// Fields added after a false If are not evaluated
+func (e *Event) If(cond bool) *Event {
+	if !cond && e.enabled {
+		e.enabled = false
+	}
+	return e
+}
+
 // Msg sends the event with the given message
 // This finalizes and writes the log entry
 func (e *Event) Msg(msg string) {
+	e.msg(msg, 1)
+}
+
+// reserveKey renames fields named key, which the logger adds itself, to "fields.<key>"
+// The input slice is not modified
+func reserveKey(fields []Field, key string) []Field {
+	for i, field := range fields {
+		if field.Key != key {
+			continue
+		}
+		renamed := append([]Field(nil), fields...)
+		for j := i; j < len(renamed); j++ {
+			if renamed[j].Key == key {
+				renamed[j].Key = reservedKeyPrefix + key
+			}
+		}
+		return renamed
+	}
+	return fields
+}
+
+// msg sends the event; skip is the number of frames between msg and the caller to report
+func (e *Event) msg(msg string, skip int) {
 	if !e.enabled {
 		return
 	}
+	if e.logger.once && !e.logger.state.firstTime(e.level, msg) {
+		return
+	}
+
+	// Resolve repeated keys so every formatter sees the same set of fields
+	contextFields, fields := applyDuplicateKeyPolicy(e.logger.fields, e.fields, e.logger.duplicateKeys)
+	fields = nestFields(e.logger.groups, fields)
+	if e.logger.name != "" {
+		fields = append([]Field{String("logger", e.logger.name)}, reserveKey(fields, "logger")...)
+	}
 
 	entry := Entry{
 		Time:          e.timestamp,
 		Level:         e.level,
 		Message:       msg,
-		Fields:        e.fields,        // Event-specific fields
-		ContextFields: e.logger.fields, // Context fields from With()
-		Caller:        getCaller(2),    // Skip: getCaller -> Msg -> actual caller
+		Fields:        fields,        // Event-specific fields
+		ContextFields: contextFields, // Context fields from With()
+	}
+	switch {
+	case e.caller != nil:
+		entry.Caller = *e.caller
+	case !e.noCaller:
+		entry.Caller = getCaller(skip + 2 + e.callerSkip) // Skip getCaller, msg and its callers
+	}
+	if e.logger.limits.enabled() {
+		e.logger.limits.apply(&entry)
+	}
+	if e.logger.fingerprint != nil {
+		fingerprint := String("fingerprint", e.logger.fingerprint(entry))
+		entry.Fields = append(reserveKey(entry.Fields, "fingerprint"), fingerprint)
+	}
+
+	if e.logger.state != nil {
+		e.logger.state.subscribers.publish(entry)
+	}
+
+	// The event may only be enabled for subscribers
+	if !e.logger.handler.Enabled(entry.Level) {
+		return
+	}
+	countEntry(entry.Level)
+
+	// Handle the entry and surface failures instead of losing them silently
+	if err := e.logger.handler.Handle(entry); err != nil {
+		reportError(e.logger.errorHandler, err, entry)
 	}
+}
 
-	// Handle the entry
-	_ = e.logger.handler.Handle(entry)
+// Timer starts timing an operation and returns a function that sends the event with
+// msg and a "duration" field holding the elapsed time, timestamped when it is called:
+//
+//	done := logger.Info().Str("table", "orders").Timer("db_query")
+//	defer done()
+func (e *Event) Timer(msg string) func() {
+	if !e.enabled {
+		return func() {}
+	}
+	start := clockNow(e.logger.clock)
+	sent := false
+	return func() {
+		if sent {
+			return
+		}
+		sent = true
+		end := clockNow(e.logger.clock)
+		e.timestamp = end
+		if e.logger.location != nil {
+			e.timestamp = end.In(e.logger.location)
+		}
+		e.fields = append(e.fields, Duration("duration", end.Sub(start)))
+		e.msg(msg, 1)
+	}
 }
 
 // Msgf sends the event with a formatted message
@@ -0,0 +1,172 @@
+package logpy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	defaultFailoverThreshold = 3
+	defaultProbeInterval     = 30 * time.Second
+)
+
+// FailoverHandler sends entries to a primary handler (e.g. a network shipper) and
+// switches to a fallback handler (e.g. a local file) after repeated failures.
+// While failed over, the primary is probed periodically with a live entry and
+// used again as soon as a probe succeeds
+type FailoverHandler struct {
+	primary       Handler
+	fallback      Handler
+	threshold     int
+	probeInterval time.Duration
+
+	mu         sync.Mutex
+	failures   int       // Consecutive primary failures
+	failedOver bool      // Entries currently go to the fallback
+	lastProbe  time.Time // Last time the primary was tried while failed over
+}
+
+// NewFailoverHandler creates a handler that fails over from primary to fallback
+// after 3 consecutive errors and probes the primary every 30 seconds
+func NewFailoverHandler(primary, fallback Handler) *FailoverHandler {
+	return &FailoverHandler{
+		primary:       primary,
+		fallback:      fallback,
+		threshold:     defaultFailoverThreshold,
+		probeInterval: defaultProbeInterval,
+	}
+}
+
+// SetThreshold sets how many consecutive primary failures trigger failover
+func (h *FailoverHandler) SetThreshold(n int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if n <= 0 {
+		n = defaultFailoverThreshold
+	}
+	h.threshold = n
+}
+
+// SetProbeInterval sets how often the primary is retried while failed over
+func (h *FailoverHandler) SetProbeInterval(d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if d <= 0 {
+		d = defaultProbeInterval
+	}
+	h.probeInterval = d
+}
+
+// FailedOver reports whether entries are currently routed to the fallback handler
+func (h *FailoverHandler) FailedOver() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.failedOver
+}
+
+// Enabled implements the Handler interface
+func (h *FailoverHandler) Enabled(level Level) bool {
+	return h.primary.Enabled(level) || h.fallback.Enabled(level)
+}
+
+// Handle implements the Handler interface
+// An entry the primary rejects is written to the fallback so it is not lost
+func (h *FailoverHandler) Handle(entry Entry) error {
+	// Evaluate lazy fields once so a retry on the fallback sees the same value
+	entry = entry.resolveLazy()
+
+	if !h.usePrimary() {
+		return h.fallback.Handle(entry)
+	}
+
+	err := h.primary.Handle(entry)
+	h.record(err)
+	if err == nil {
+		return nil
+	}
+	if fbErr := h.fallback.Handle(entry); fbErr != nil {
+		return errors.Join(err, fbErr)
+	}
+	return nil
+}
+
+// usePrimary reports whether the next entry should go to the primary handler
+func (h *FailoverHandler) usePrimary() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.failedOver {
+		return true
+	}
+	if time.Since(h.lastProbe) < h.probeInterval {
+		return false
+	}
+	h.lastProbe = time.Now()
+	return true
+}
+
+// record updates the failure state after a primary delivery attempt
+func (h *FailoverHandler) record(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err == nil {
+		h.failures = 0
+		h.failedOver = false
+		return
+	}
+	h.failures++
+	if !h.failedOver && h.failures >= h.threshold {
+		h.failedOver = true
+		h.lastProbe = time.Now()
+	}
+}
+
+// WithFields implements the Handler interface
+func (h *FailoverHandler) WithFields(fields []Field) Handler {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return &FailoverHandler{
+		primary:       h.primary.WithFields(fields),
+		fallback:      h.fallback.WithFields(fields),
+		threshold:     h.threshold,
+		probeInterval: h.probeInterval,
+	}
+}
+
+// Flush flushes both handlers
+func (h *FailoverHandler) Flush() error {
+	var errs []error
+	for _, handler := range []Handler{h.primary, h.fallback} {
+		if f, ok := handler.(Flusher); ok {
+			errs = append(errs, f.Flush())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Drain implements the Drainer interface for asynchronous primary or fallback handlers
+func (h *FailoverHandler) Drain(ctx context.Context) (int, error) {
+	var dropped int
+	var errs []error
+	for _, handler := range []Handler{h.primary, h.fallback} {
+		if d, ok := handler.(Drainer); ok {
+			n, err := d.Drain(ctx)
+			dropped += n
+			errs = append(errs, err)
+		}
+	}
+	return dropped, errors.Join(errs...)
+}
+
+// Close closes both handlers
+func (h *FailoverHandler) Close() error {
+	var errs []error
+	for _, handler := range []Handler{h.primary, h.fallback} {
+		if c, ok := handler.(io.Closer); ok {
+			errs = append(errs, c.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,113 @@
+package logpy
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFailoverHandlerRouting(t *testing.T) {
+	tests := []struct {
+		name         string
+		entries      int
+		fail         bool // Whether the primary fails
+		wantFailed   bool
+		wantPrimary  int
+		wantFallback int
+	}{
+		{"healthy", 3, false, false, 3, 0},
+		{"below threshold", 1, true, false, 1, 1},
+		{"at threshold", 2, true, true, 2, 2},
+		{"failed over", 4, true, true, 2, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &flakyHandler{fail: tt.fail}
+			fallback := &flakyHandler{}
+			h := NewFailoverHandler(primary, fallback)
+			h.SetThreshold(2)
+			h.SetProbeInterval(time.Hour)
+
+			for i := 0; i < tt.entries; i++ {
+				if err := h.Handle(Entry{Level: InfoLevel}); err != nil {
+					t.Fatalf("Handle = %v, want nil once the fallback takes the entry", err)
+				}
+			}
+
+			if got := h.FailedOver(); got != tt.wantFailed {
+				t.Errorf("FailedOver() = %v, want %v", got, tt.wantFailed)
+			}
+			if got := primary.callCount(); got != tt.wantPrimary {
+				t.Errorf("primary calls = %d, want %d", got, tt.wantPrimary)
+			}
+			if got := fallback.callCount(); got != tt.wantFallback {
+				t.Errorf("fallback calls = %d, want %d", got, tt.wantFallback)
+			}
+		})
+	}
+}
+
+func TestFailoverHandlerProbeRecovers(t *testing.T) {
+	primary := &flakyHandler{fail: true}
+	fallback := &flakyHandler{}
+	h := NewFailoverHandler(primary, fallback)
+	h.SetThreshold(1)
+	h.SetProbeInterval(time.Hour)
+
+	h.Handle(Entry{Level: InfoLevel})
+	if !h.FailedOver() {
+		t.Fatal("FailedOver() = false after reaching the threshold")
+	}
+
+	// A failed probe keeps the fallback in use
+	h.SetProbeInterval(time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	h.Handle(Entry{Level: InfoLevel})
+	if !h.FailedOver() || primary.callCount() != 2 {
+		t.Fatalf("after failed probe: FailedOver() = %v, primary calls = %d, want true, 2", h.FailedOver(), primary.callCount())
+	}
+
+	// A successful probe switches back to the primary
+	primary.setFail(false)
+	time.Sleep(5 * time.Millisecond)
+	h.Handle(Entry{Level: InfoLevel})
+	h.SetProbeInterval(time.Hour)
+	if h.FailedOver() {
+		t.Fatal("FailedOver() = true after a successful probe")
+	}
+	h.Handle(Entry{Level: InfoLevel})
+	if got := primary.callCount(); got != 4 {
+		t.Fatalf("primary calls = %d, want 4", got)
+	}
+	if got := fallback.callCount(); got != 2 {
+		t.Fatalf("fallback calls = %d, want 2", got)
+	}
+}
+
+func TestFailoverHandlerJoinsErrors(t *testing.T) {
+	primary := &flakyHandler{fail: true}
+	fallback := &flakyHandler{fail: true}
+	h := NewFailoverHandler(primary, fallback)
+
+	err := h.Handle(Entry{Level: InfoLevel})
+	if !errors.Is(err, errTestBackend) {
+		t.Fatalf("Handle = %v, want the primary and fallback errors", err)
+	}
+}
+
+func TestFailoverHandlerEnabled(t *testing.T) {
+	h := NewFailoverHandler(&flakyHandler{level: ErrorLevel}, &flakyHandler{level: InfoLevel})
+	tests := []struct {
+		level Level
+		want  bool
+	}{
+		{DebugLevel, false},
+		{InfoLevel, true},
+		{ErrorLevel, true},
+	}
+	for _, tt := range tests {
+		if got := h.Enabled(tt.level); got != tt.want {
+			t.Errorf("Enabled(%s) = %v, want %v", tt.level, got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,12 @@
 package logpy
 
-import "time"
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"sort"
+	"time"
+)
 
 // FieldType represents the type of a field value
 type FieldType uint8
@@ -15,6 +21,24 @@ const (
 	DurationType
 	ErrorType
 	AnyType
+	UintType
+	Uint32Type
+	Uint64Type
+	Int32Type
+	Int16Type
+	Int8Type
+	Float32Type
+	StringsType
+	IntsType
+	Float64sType
+	BoolsType
+	DurationsType
+	ErrorsType
+	IPAddrType
+	IPPrefixType
+	MACAddrType
+	LazyType
+	GroupType
 )
 
 // Field represents a strongly-typed key-value pair for structured logging
@@ -39,6 +63,41 @@ func Int64(key string, val int64) Field {
 	return Field{Key: key, Type: Int64Type, Value: val}
 }
 
+// Int32 creates an int32 field
+func Int32(key string, val int32) Field {
+	return Field{Key: key, Type: Int32Type, Value: val}
+}
+
+// Int16 creates an int16 field
+func Int16(key string, val int16) Field {
+	return Field{Key: key, Type: Int16Type, Value: val}
+}
+
+// Int8 creates an int8 field
+func Int8(key string, val int8) Field {
+	return Field{Key: key, Type: Int8Type, Value: val}
+}
+
+// Uint creates a uint field
+func Uint(key string, val uint) Field {
+	return Field{Key: key, Type: UintType, Value: val}
+}
+
+// Uint32 creates a uint32 field
+func Uint32(key string, val uint32) Field {
+	return Field{Key: key, Type: Uint32Type, Value: val}
+}
+
+// Uint64 creates a uint64 field
+func Uint64(key string, val uint64) Field {
+	return Field{Key: key, Type: Uint64Type, Value: val}
+}
+
+// Float32 creates a float32 field
+func Float32(key string, val float32) Field {
+	return Field{Key: key, Type: Float32Type, Value: val}
+}
+
 // Float64 creates a float64 field
 func Float64(key string, val float64) Field {
 	return Field{Key: key, Type: Float64Type, Value: val}
@@ -59,15 +118,369 @@ func Duration(key string, val time.Duration) Field {
 	return Field{Key: key, Type: DurationType, Value: val}
 }
 
+// Strs creates a string slice field
+func Strs(key string, vals []string) Field {
+	return Field{Key: key, Type: StringsType, Value: vals}
+}
+
+// Ints creates an int slice field
+func Ints(key string, vals []int) Field {
+	return Field{Key: key, Type: IntsType, Value: vals}
+}
+
+// Floats creates a float64 slice field
+func Floats(key string, vals []float64) Field {
+	return Field{Key: key, Type: Float64sType, Value: vals}
+}
+
+// Bools creates a boolean slice field
+func Bools(key string, vals []bool) Field {
+	return Field{Key: key, Type: BoolsType, Value: vals}
+}
+
+// Durs creates a duration slice field
+func Durs(key string, vals []time.Duration) Field {
+	return Field{Key: key, Type: DurationsType, Value: vals}
+}
+
+// IPAddr creates an IP address field encoded in its canonical string form
+func IPAddr(key string, val netip.Addr) Field {
+	return Field{Key: key, Type: IPAddrType, Value: val.String()}
+}
+
+// IPPrefix creates an IP prefix (CIDR) field encoded in its canonical string form
+func IPPrefix(key string, val netip.Prefix) Field {
+	return Field{Key: key, Type: IPPrefixType, Value: val.String()}
+}
+
+// MACAddr creates a hardware address field encoded as colon-separated hex
+func MACAddr(key string, val net.HardwareAddr) Field {
+	return Field{Key: key, Type: MACAddrType, Value: val.String()}
+}
+
 // Error creates an error field
 func Error(err error) Field {
 	if err == nil {
 		return Field{Key: "error", Type: ErrorType, Value: nil}
 	}
-	return Field{Key: "error", Type: ErrorType, Value: err.Error()}
+	return Field{Key: "error", Type: ErrorType, Value: safeError(err)}
+}
+
+// Errs creates a field holding the messages of multiple errors
+// Nil errors are skipped
+func Errs(key string, errs []error) Field {
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		if err != nil {
+			msgs = append(msgs, safeError(err))
+		}
+	}
+	return Field{Key: key, Type: ErrorsType, Value: msgs}
+}
+
+// Lazy creates a field whose value is computed by fn only when the entry is handled
+// Use it for expensive values that should cost nothing when the level is disabled
+func Lazy(key string, fn func() interface{}) Field {
+	return Field{Key: key, Type: LazyType, Value: fn}
 }
 
 // Any creates a field with any value type (uses reflection, slower)
 func Any(key string, val interface{}) Field {
 	return Field{Key: key, Type: AnyType, Value: val}
 }
+
+// Group creates a field that nests fields under key
+// JSON output renders it as an object, console output prefixes the keys with "key."
+func Group(key string, fields ...Field) Field {
+	return Field{Key: key, Type: GroupType, Value: fields}
+}
+
+// groupFields returns the fields nested in a group field
+func groupFields(field Field) []Field {
+	fields, _ := field.Value.([]Field)
+	return fields
+}
+
+// nestFields wraps fields in one group per name, outermost first
+func nestFields(groups []string, fields []Field) []Field {
+	if len(groups) == 0 || len(fields) == 0 {
+		return fields
+	}
+	for i := len(groups) - 1; i >= 0; i-- {
+		fields = []Field{Group(groups[i], fields...)}
+	}
+	return fields
+}
+
+// mergeGroups appends extra to fields, merging groups with the same key so a
+// group appears once no matter how many times fields were added to it
+// With override, other fields replace earlier fields with the same key
+func mergeGroups(fields, extra []Field, override bool) []Field {
+	merged := make([]Field, 0, len(fields)+len(extra))
+	merged = append(merged, fields...)
+	for _, field := range extra {
+		idx := -1
+		if field.Type == GroupType {
+			for i, existing := range merged {
+				if existing.Type == GroupType && existing.Key == field.Key {
+					idx = i
+					break
+				}
+			}
+		}
+		if idx >= 0 {
+			merged[idx] = Group(field.Key, mergeGroups(groupFields(merged[idx]), groupFields(field), override)...)
+			continue
+		}
+		if override {
+			merged = removeFields(merged, map[string]bool{field.Key: true}, "")
+		}
+		merged = append(merged, field)
+	}
+	return merged
+}
+
+// removeFields returns fields without those whose key, prefixed by the keys of
+// the enclosing groups and prefix, is in keys. Groups left empty are dropped
+func removeFields(fields []Field, keys map[string]bool, prefix string) []Field {
+	kept := fields[:0:0]
+	for _, field := range fields {
+		key := prefix + field.Key
+		if keys[key] {
+			continue
+		}
+		if field.Type == GroupType {
+			members := removeFields(groupFields(field), keys, key+".")
+			if len(members) == 0 {
+				continue
+			}
+			field = Group(field.Key, members...)
+		}
+		kept = append(kept, field)
+	}
+	return kept
+}
+
+// flattenGroups replaces group fields by their members with dot-prefixed keys
+// The original slice is returned unchanged when it holds no groups
+func flattenGroups(fields []Field) []Field {
+	hasGroup := false
+	for _, field := range fields {
+		if field.Type == GroupType {
+			hasGroup = true
+			break
+		}
+	}
+	if !hasGroup {
+		return fields
+	}
+
+	flat := make([]Field, 0, len(fields))
+	for _, field := range fields {
+		if field.Type != GroupType {
+			flat = append(flat, field)
+			continue
+		}
+		for _, member := range flattenGroups(groupFields(field)) {
+			member.Key = field.Key + "." + member.Key
+			flat = append(flat, member)
+		}
+	}
+	return flat
+}
+
+// Value creates a field with the typed constructor matching val, falling back to Any
+// Nested map[string]interface{} values become groups, and a Field value takes key
+func Value(key string, val interface{}) Field {
+	switch v := val.(type) {
+	case string:
+		return String(key, v)
+	case int:
+		return Int(key, v)
+	case int64:
+		return Int64(key, v)
+	case int32:
+		return Int32(key, v)
+	case int16:
+		return Int16(key, v)
+	case int8:
+		return Int8(key, v)
+	case uint:
+		return Uint(key, v)
+	case uint32:
+		return Uint32(key, v)
+	case uint64:
+		return Uint64(key, v)
+	case float32:
+		return Float32(key, v)
+	case float64:
+		return Float64(key, v)
+	case bool:
+		return Bool(key, v)
+	case time.Time:
+		return Time(key, v)
+	case time.Duration:
+		return Duration(key, v)
+	case []string:
+		return Strs(key, v)
+	case []int:
+		return Ints(key, v)
+	case []float64:
+		return Floats(key, v)
+	case []bool:
+		return Bools(key, v)
+	case []time.Duration:
+		return Durs(key, v)
+	case []error:
+		return Errs(key, v)
+	case netip.Addr:
+		return IPAddr(key, v)
+	case netip.Prefix:
+		return IPPrefix(key, v)
+	case net.HardwareAddr:
+		return MACAddr(key, v)
+	case error:
+		return Field{Key: key, Type: ErrorType, Value: safeError(v)}
+	case fmt.Stringer:
+		return String(key, safeString(v))
+	case map[string]interface{}:
+		return Group(key, FieldsFromMap(v)...)
+	case Field:
+		v.Key = key
+		return v
+	default:
+		return Any(key, val)
+	}
+}
+
+// FieldsFromMap converts a map to typed fields, sorted by key so output is stable
+func FieldsFromMap(m map[string]interface{}) []Field {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fields := make([]Field, len(keys))
+	for i, key := range keys {
+		fields[i] = Value(key, m[key])
+	}
+	return fields
+}
+
+// resolveLazyFields returns fields with every lazy value evaluated, including
+// those inside groups
+// The original slice is returned unchanged when it holds no lazy fields
+func resolveLazyFields(fields []Field) []Field {
+	hasLazy := false
+	for _, field := range fields {
+		if field.Type == LazyType || field.Type == GroupType {
+			hasLazy = true
+			break
+		}
+	}
+	if !hasLazy {
+		return fields
+	}
+
+	resolved := make([]Field, len(fields))
+	for i, field := range fields {
+		switch field.Type {
+		case LazyType:
+			var val interface{}
+			if fn, ok := field.Value.(func() interface{}); ok && fn != nil {
+				val = fn()
+			}
+			field = Field{Key: field.Key, Type: AnyType, Value: val}
+		case GroupType:
+			field = Group(field.Key, resolveLazyFields(groupFields(field))...)
+		}
+		resolved[i] = field
+	}
+	return resolved
+}
+
+// applyDuplicateKeyPolicy resolves repeated keys across context and event fields, and
+// within each of them. Context fields are treated as preceding event fields, so with
+// the default keep-last policy an event field replaces a context field with its key.
+// The input slices are not modified; without duplicates they are returned as they are
+func applyDuplicateKeyPolicy(contextFields, eventFields []Field, policy DuplicateKeyPolicy) ([]Field, []Field) {
+	if !hasDuplicateKeys(contextFields, eventFields) {
+		return contextFields, eventFields
+	}
+
+	total := len(contextFields) + len(eventFields)
+	all := make([]Field, 0, total)
+	all = append(all, contextFields...)
+	all = append(all, eventFields...)
+
+	keep := make([]bool, total)
+	switch policy {
+	case DuplicateKeepFirst:
+		for i, field := range all {
+			keep[i] = !containsKey(all[:i], field.Key)
+		}
+	case DuplicateSuffixIndex:
+		original := append([]Field(nil), all...)
+		next := make(map[string]int)
+		for i, field := range all {
+			keep[i] = true
+			if !containsKey(all[:i], field.Key) {
+				continue
+			}
+			// Find the next suffixed key not already used by any field
+			for {
+				next[field.Key]++
+				candidate := fmt.Sprintf("%s_%d", field.Key, next[field.Key])
+				if !containsKey(all[:i], candidate) && !containsKey(original, candidate) {
+					all[i].Key = candidate
+					break
+				}
+			}
+		}
+	default:
+		for i, field := range all {
+			keep[i] = !containsKey(all[i+1:], field.Key)
+		}
+	}
+
+	ctx := make([]Field, 0, len(contextFields))
+	evt := make([]Field, 0, len(eventFields))
+	for i, field := range all {
+		if !keep[i] {
+			continue
+		}
+		if i < len(contextFields) {
+			ctx = append(ctx, field)
+		} else {
+			evt = append(evt, field)
+		}
+	}
+	return ctx, evt
+}
+
+// hasDuplicateKeys reports whether any key repeats across or within the field lists
+// Entries carry few fields, so a linear scan is cheaper than building a map
+func hasDuplicateKeys(contextFields, eventFields []Field) bool {
+	for i, field := range contextFields {
+		if containsKey(contextFields[:i], field.Key) {
+			return true
+		}
+	}
+	for i, field := range eventFields {
+		if containsKey(contextFields, field.Key) || containsKey(eventFields[:i], field.Key) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsKey reports whether a field in fields has key
+func containsKey(fields []Field, key string) bool {
+	for _, field := range fields {
+		if field.Key == key {
+			return true
+		}
+	}
+	return false
+}
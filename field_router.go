@@ -0,0 +1,256 @@
+package logpy
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrNoRoute is returned when an entry has no usable routing value and there is no fallback
+var ErrNoRoute = errors.New("logpy: no route for entry")
+
+const (
+	defaultMaxRoutes     = 64
+	maxRouteValueLength  = 64
+	defaultTenantDirName = "tenant-"
+)
+
+// HandlerFactory creates the handler for a routing value, e.g. a tenant ID
+type HandlerFactory func(value string) (Handler, error)
+
+// FieldRouterHandler sends each entry to a handler chosen by the value of one of its
+// fields, e.g. a file per tenant. Handlers are created on first use and kept in an LRU;
+// when more than the maximum are open, the least recently used one is closed.
+// Entries without the field, or with a value that is not a safe name (letters, digits,
+// '.', '_' and '-', at most 64 characters), go to the fallback handler
+type FieldRouterHandler struct {
+	key      string
+	level    Level
+	factory  HandlerFactory
+	fallback Handler // Optional
+	maxOpen  int
+
+	mu     sync.Mutex // Held while an entry is written so a route is not closed mid-write
+	routes map[string]*list.Element
+	lru    *list.List // Most recently used at the front
+	closed bool
+}
+
+// fieldRoute is an open route in the LRU
+type fieldRoute struct {
+	value   string
+	handler Handler
+}
+
+// NewFieldRouterHandler creates a handler that routes entries at or above level by the
+// value of the field named key, creating handlers with factory. fallback may be nil
+func NewFieldRouterHandler(key string, level Level, factory HandlerFactory, fallback Handler) *FieldRouterHandler {
+	return &FieldRouterHandler{
+		key:      key,
+		level:    level,
+		factory:  factory,
+		fallback: fallback,
+		maxOpen:  defaultMaxRoutes,
+		routes:   make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// NewTenantFileHandler routes entries by the field named key to daily files under
+// baseDir/tenant-<value>/, keeping at most maxDaysToKeep days per tenant
+func NewTenantFileHandler(baseDir, key string, level Level, maxDaysToKeep int, fallback Handler) *FieldRouterHandler {
+	factory := func(value string) (Handler, error) {
+		dir := filepath.Join(baseDir, defaultTenantDirName+value)
+		return NewDailyFileHandler(dir, "", level, maxDaysToKeep, false, DefaultColorConfig())
+	}
+	return NewFieldRouterHandler(key, level, factory, fallback)
+}
+
+// SetMaxOpen sets how many routed handlers are kept open (default 64)
+func (h *FieldRouterHandler) SetMaxOpen(n int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if n <= 0 {
+		n = defaultMaxRoutes
+	}
+	h.maxOpen = n
+	h.evict()
+}
+
+// Open returns the number of routed handlers currently open
+func (h *FieldRouterHandler) Open() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.lru.Len()
+}
+
+// Enabled implements the Handler interface
+func (h *FieldRouterHandler) Enabled(level Level) bool {
+	return level.AtLeast(h.level) || (h.fallback != nil && h.fallback.Enabled(level))
+}
+
+// Handle implements the Handler interface
+func (h *FieldRouterHandler) Handle(entry Entry) error {
+	value, ok := h.routeValue(entry)
+	if !ok || !entry.Level.AtLeast(h.level) {
+		return h.handleFallback(entry, nil)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return ErrHandlerClosed
+	}
+	handler, err := h.route(value)
+	if err != nil {
+		return h.handleFallback(entry, err)
+	}
+	return handler.Handle(entry)
+}
+
+// routeValue returns the routing value of entry and whether it is a safe name
+func (h *FieldRouterHandler) routeValue(entry Entry) (string, bool) {
+	field, ok := findField(entry, h.key)
+	if !ok || field.Value == nil {
+		return "", false
+	}
+	value := fmt.Sprint(field.Value)
+	return value, validRouteValue(value)
+}
+
+// validRouteValue reports whether value can safely be used in a file path
+func validRouteValue(value string) bool {
+	if value == "" || value == "." || value == ".." || len(value) > maxRouteValueLength {
+		return false
+	}
+	for _, r := range value {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// route returns the handler for value, creating it if needed; h.mu must be held
+func (h *FieldRouterHandler) route(value string) (Handler, error) {
+	if elem, ok := h.routes[value]; ok {
+		h.lru.MoveToFront(elem)
+		return elem.Value.(*fieldRoute).handler, nil
+	}
+
+	handler, err := h.factory(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create handler for %s=%s: %w", h.key, value, err)
+	}
+	h.routes[value] = h.lru.PushFront(&fieldRoute{value: value, handler: handler})
+	h.evict()
+	return handler, nil
+}
+
+// evict closes the least recently used handlers beyond the limit; h.mu must be held
+func (h *FieldRouterHandler) evict() {
+	for h.lru.Len() > h.maxOpen {
+		elem := h.lru.Back()
+		route := elem.Value.(*fieldRoute)
+		h.lru.Remove(elem)
+		delete(h.routes, route.value)
+		if err := closeHandler(route.handler); err != nil {
+			fmt.Fprintf(os.Stderr, "error closing log route %s=%s: %v\n", h.key, route.value, err)
+		}
+	}
+}
+
+// handleFallback writes entry to the fallback handler, reporting err along with any failure
+func (h *FieldRouterHandler) handleFallback(entry Entry, err error) error {
+	if h.fallback == nil {
+		if err != nil {
+			return err
+		}
+		return ErrNoRoute
+	}
+	if fbErr := h.fallback.Handle(entry); fbErr != nil {
+		return errors.Join(err, fbErr)
+	}
+	return err
+}
+
+// closeHandler flushes and closes handler if it supports it
+func closeHandler(handler Handler) error {
+	if c, ok := handler.(io.Closer); ok {
+		return c.Close()
+	}
+	if f, ok := handler.(Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// WithFields implements the Handler interface
+// Routes are shared, fields are managed by the logger
+func (h *FieldRouterHandler) WithFields(fields []Field) Handler {
+	return h
+}
+
+// handlers returns the open routed handlers and the fallback
+func (h *FieldRouterHandler) handlers() []Handler {
+	var handlers []Handler
+	for elem := h.lru.Front(); elem != nil; elem = elem.Next() {
+		handlers = append(handlers, elem.Value.(*fieldRoute).handler)
+	}
+	if h.fallback != nil {
+		handlers = append(handlers, h.fallback)
+	}
+	return handlers
+}
+
+// Flush flushes all open routes and the fallback
+func (h *FieldRouterHandler) Flush() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var errs []error
+	for _, handler := range h.handlers() {
+		if f, ok := handler.(Flusher); ok {
+			errs = append(errs, f.Flush())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Drain implements the Drainer interface for asynchronous routed or fallback handlers
+func (h *FieldRouterHandler) Drain(ctx context.Context) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var dropped int
+	var errs []error
+	for _, handler := range h.handlers() {
+		if d, ok := handler.(Drainer); ok {
+			n, err := d.Drain(ctx)
+			dropped += n
+			errs = append(errs, err)
+		}
+	}
+	return dropped, errors.Join(errs...)
+}
+
+// Close closes all open routes and the fallback
+func (h *FieldRouterHandler) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	var errs []error
+	for _, handler := range h.handlers() {
+		if c, ok := handler.(io.Closer); ok {
+			errs = append(errs, c.Close())
+		}
+	}
+	h.routes = make(map[string]*list.Element)
+	h.lru.Init()
+	return errors.Join(errs...)
+}
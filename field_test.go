@@ -0,0 +1,126 @@
+package logpy
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingHandler records every entry it handles
+type recordingHandler struct {
+	mu      sync.Mutex
+	entries []Entry
+}
+
+func (r *recordingHandler) Enabled(Level) bool                { return true }
+func (r *recordingHandler) WithFields(fields []Field) Handler { return r }
+
+func (r *recordingHandler) Handle(entry Entry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+// last returns the most recently handled entry
+func (r *recordingHandler) last(t *testing.T) Entry {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.entries) == 0 {
+		t.Fatal("no entries handled")
+	}
+	return r.entries[len(r.entries)-1]
+}
+
+// describeFields renders fields as key=value pairs for comparison
+func describeFields(fields []Field) string {
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		parts[i] = field.Key + "=" + field.Value.(string)
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestApplyDuplicateKeyPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    DuplicateKeyPolicy
+		context   []Field
+		event     []Field
+		wantCtx   string
+		wantEvent string
+	}{
+		{"no duplicates", DuplicateKeepFirst,
+			[]Field{String("a", "1")}, []Field{String("b", "2")}, "a=1", "b=2"},
+
+		{"default context vs event", "",
+			[]Field{String("a", "1"), String("b", "2")}, []Field{String("a", "3")}, "b=2", "a=3"},
+		{"default event vs event", "",
+			nil, []Field{String("a", "1"), String("a", "2")}, "", "a=2"},
+		{"keep-last context vs event", DuplicateKeepLast,
+			[]Field{String("a", "1")}, []Field{String("a", "2")}, "", "a=2"},
+		{"keep-last event vs event", DuplicateKeepLast,
+			[]Field{String("b", "0")}, []Field{String("a", "1"), String("b", "2"), String("a", "3")}, "", "b=2 a=3"},
+
+		{"keep-first context vs event", DuplicateKeepFirst,
+			[]Field{String("a", "1")}, []Field{String("a", "2"), String("b", "3")}, "a=1", "b=3"},
+		{"keep-first event vs event", DuplicateKeepFirst,
+			nil, []Field{String("a", "1"), String("a", "2")}, "", "a=1"},
+		{"keep-first within context", DuplicateKeepFirst,
+			[]Field{String("a", "1"), String("a", "2")}, nil, "a=1", ""},
+
+		{"suffix-index context vs event", DuplicateSuffixIndex,
+			[]Field{String("a", "1")}, []Field{String("a", "2")}, "a=1", "a_1=2"},
+		{"suffix-index event vs event", DuplicateSuffixIndex,
+			nil, []Field{String("a", "1"), String("a", "2"), String("a", "3")}, "", "a=1 a_1=2 a_2=3"},
+		{"suffix-index skips taken keys", DuplicateSuffixIndex,
+			nil, []Field{String("a", "1"), String("a", "2"), String("a_1", "3")}, "", "a=1 a_2=2 a_1=3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			context := append([]Field(nil), tt.context...)
+			event := append([]Field(nil), tt.event...)
+			gotCtx, gotEvent := applyDuplicateKeyPolicy(context, event, tt.policy)
+			if got := describeFields(gotCtx); got != tt.wantCtx {
+				t.Errorf("context fields = %q, want %q", got, tt.wantCtx)
+			}
+			if got := describeFields(gotEvent); got != tt.wantEvent {
+				t.Errorf("event fields = %q, want %q", got, tt.wantEvent)
+			}
+			if describeFields(context) != describeFields(tt.context) || describeFields(event) != describeFields(tt.event) {
+				t.Error("input fields were modified")
+			}
+		})
+	}
+}
+
+func TestLoggerDuplicateKeys(t *testing.T) {
+	tests := []struct {
+		policy    DuplicateKeyPolicy
+		wantCtx   string
+		wantEvent string
+	}{
+		{"", "", "a=3"},
+		{DuplicateKeepLast, "", "a=3"},
+		{DuplicateKeepFirst, "a=1", ""},
+		{DuplicateSuffixIndex, "a=1", "a_1=2 a_2=3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			handler := &recordingHandler{}
+			logger := New(handler).WithDuplicateKeys(tt.policy).With(String("a", "1"))
+			logger.Info().Str("a", "2").Str("a", "3").Msg("duplicates")
+
+			entry := handler.last(t)
+			if got := describeFields(entry.ContextFields); got != tt.wantCtx {
+				t.Errorf("context fields = %q, want %q", got, tt.wantCtx)
+			}
+			if got := describeFields(entry.Fields); got != tt.wantEvent {
+				t.Errorf("event fields = %q, want %q", got, tt.wantEvent)
+			}
+		})
+	}
+}
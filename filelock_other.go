@@ -0,0 +1,16 @@
+//go:build !windows && !linux && !darwin && !freebsd && !netbsd && !openbsd && !dragonfly
+
+package logpy
+
+import "os"
+
+// lockFile is a no-op on platforms without flock; O_APPEND still keeps
+// each single-write entry intact on local file systems
+func lockFile(f *os.File) error {
+	return nil
+}
+
+// unlockFile is a no-op on platforms without flock
+func unlockFile(f *os.File) error {
+	return nil
+}
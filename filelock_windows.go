@@ -0,0 +1,37 @@
+//go:build windows
+
+package logpy
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// lockfileExclusiveLock requests an exclusive lock from LockFileEx
+const lockfileExclusiveLock = 0x00000002
+
+var (
+	procLockFileEx   = kernel32.NewProc("LockFileEx")
+	procUnlockFileEx = kernel32.NewProc("UnlockFileEx")
+)
+
+// lockFile takes an exclusive lock on f, blocking until it is available
+func lockFile(f *os.File) error {
+	var ol syscall.Overlapped
+	ret, _, err := procLockFileEx.Call(f.Fd(), lockfileExclusiveLock, 0, 1, 0, uintptr(unsafe.Pointer(&ol)))
+	if ret == 0 {
+		return err
+	}
+	return nil
+}
+
+// unlockFile releases the lock on f
+func unlockFile(f *os.File) error {
+	var ol syscall.Overlapped
+	ret, _, err := procUnlockFileEx.Call(f.Fd(), 0, 1, 0, uintptr(unsafe.Pointer(&ol)))
+	if ret == 0 {
+		return err
+	}
+	return nil
+}
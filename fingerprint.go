@@ -0,0 +1,68 @@
+package logpy
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"strconv"
+)
+
+// FingerprintFunc computes a key that groups similar entries, e.g. for dedup and aggregation
+type FingerprintFunc func(entry Entry) string
+
+// DefaultFingerprint groups entries by level and message
+var DefaultFingerprint FingerprintFunc = FingerprintFields()
+
+// FingerprintFields returns a fingerprint of the level, the message and the values
+// of the given field keys, as a 16 character hex hash
+func FingerprintFields(keys ...string) FingerprintFunc {
+	return func(entry Entry) string {
+		h := fnv.New64a()
+		writeFingerprint(h, entry.Level.String(), entry.Message)
+		for _, key := range keys {
+			if f, ok := findField(entry, key); ok {
+				writeFingerprint(h, key, fmt.Sprint(resolveLazyFields([]Field{f})[0].Value))
+			}
+		}
+		return fmt.Sprintf("%016x", h.Sum64())
+	}
+}
+
+// fingerprintAllFields fingerprints the level, the message and every field
+func fingerprintAllFields(entry Entry) string {
+	h := fnv.New64a()
+	writeFingerprint(h, entry.Level.String(), entry.Message)
+	for _, fields := range [][]Field{entry.ContextFields, entry.Fields} {
+		for _, f := range flattenGroups(resolveLazyFields(fields)) {
+			writeFingerprint(h, f.Key, fmt.Sprint(f.Value))
+		}
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// writeFingerprint adds length-prefixed strings to h so adjacent values cannot collide
+func writeFingerprint(h io.Writer, values ...string) {
+	for _, v := range values {
+		h.Write([]byte(strconv.Itoa(len(v))))
+		h.Write([]byte{':'})
+		h.Write([]byte(v))
+	}
+}
+
+// findField returns the field with key, preferring event fields over context fields
+// Fields inside groups are found by their dotted key, e.g. "db.host"
+func findField(entry Entry, key string) (Field, bool) {
+	fields := flattenGroups(entry.Fields)
+	for i := len(fields) - 1; i >= 0; i-- {
+		if fields[i].Key == key {
+			return fields[i], true
+		}
+	}
+	contextFields := flattenGroups(entry.ContextFields)
+	for i := len(contextFields) - 1; i >= 0; i-- {
+		if contextFields[i].Key == key {
+			return contextFields[i], true
+		}
+	}
+	return Field{}, false
+}
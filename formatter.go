@@ -1,9 +1,12 @@
 package logpy
 
 import (
-	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 // Color codes for terminal output
@@ -23,16 +26,41 @@ type ColorConfig struct {
 	Warn  string
 	Error string
 	Reset string
+
+	// Timestamp is the color of the timestamp (cyan when empty)
+	Timestamp string
+	// Caller is the color of the file:line caller info (uncolored when empty)
+	Caller string
+
+	// Message, Key and Value color the message and event field keys/values (uncolored when empty)
+	Message string
+	Key     string
+	Value   string
+	// Context colors the whole context block after "|" (falls back to Key/Value when empty)
+	Context string
+}
+
+// paint wraps s in color when color is non-empty
+func (c ColorConfig) paint(color, s string) string {
+	if color == "" {
+		return s
+	}
+	reset := c.Reset
+	if reset == "" {
+		reset = colorReset
+	}
+	return color + s + reset
 }
 
 // DefaultColorConfig returns the default color configuration
 func DefaultColorConfig() ColorConfig {
 	return ColorConfig{
-		Debug: colorGray,
-		Info:  colorBlue,
-		Warn:  colorYellow,
-		Error: colorRed,
-		Reset: colorReset,
+		Debug:     colorGray,
+		Info:      colorBlue,
+		Warn:      colorYellow,
+		Error:     colorRed,
+		Reset:     colorReset,
+		Timestamp: colorCyan,
 	}
 }
 
@@ -41,69 +69,340 @@ type Formatter interface {
 	Format(entry Entry) ([]byte, error)
 }
 
+// HeaderFormatter is implemented by formatters whose files start with a header row,
+// such as CSVFormatter. File handlers write it at the top of every new or rotated file
+type HeaderFormatter interface {
+	Formatter
+	Header() []byte
+}
+
+// formatterHeader returns the header of f, or nil when it has none
+func formatterHeader(f Formatter) []byte {
+	if hf, ok := f.(HeaderFormatter); ok {
+		return hf.Header()
+	}
+	return nil
+}
+
+// TimestampEncoding defines how the entry timestamp is encoded in JSON output
+type TimestampEncoding string
+
+const (
+	TimestampRFC3339     TimestampEncoding = "rfc3339"      // String formatted with TimestampFormat (default)
+	TimestampEpochSecs   TimestampEncoding = "epoch_secs"   // Integer seconds since the Unix epoch
+	TimestampEpochMillis TimestampEncoding = "epoch_millis" // Integer milliseconds since the Unix epoch
+	TimestampEpochNanos  TimestampEncoding = "epoch_nanos"  // Integer nanoseconds since the Unix epoch
+	TimestampEpochFloat  TimestampEncoding = "epoch_float"  // Floating-point seconds since the Unix epoch
+)
+
+// encodeTime converts t according to enc; string encodings use layout
+func encodeTime(t time.Time, enc TimestampEncoding, layout string) interface{} {
+	switch enc {
+	case TimestampEpochSecs:
+		return t.Unix()
+	case TimestampEpochMillis:
+		return t.UnixMilli()
+	case TimestampEpochNanos:
+		return t.UnixNano()
+	case TimestampEpochFloat:
+		return float64(t.UnixNano()) / 1e9
+	}
+	return t.Format(layout)
+}
+
+// LevelEncoding defines how the entry level is encoded in JSON and binary output
+type LevelEncoding string
+
+const (
+	LevelUpper    LevelEncoding = "upper"    // Upper-case name, e.g. "INFO" (default)
+	LevelLower    LevelEncoding = "lower"    // Lower-case name, e.g. "info"
+	LevelNumber   LevelEncoding = "number"   // Level number: 0 debug, 1 info, 2 warn, 3 error (custom levels in between are fractional)
+	LevelSeverity LevelEncoding = "severity" // RFC 5424 severity: 7 debug, 6 info, 4 warn, 3 error
+)
+
+// encodeLevel converts level according to enc; labels replace the names of the string encodings
+func encodeLevel(level Level, enc LevelEncoding, labels map[Level]string) interface{} {
+	switch enc {
+	case LevelNumber:
+		return levelNumber(level)
+	case LevelSeverity:
+		return level.Severity()
+	}
+	if label, ok := labels[level]; ok {
+		return label
+	}
+	if enc == LevelLower {
+		return strings.ToLower(level.String())
+	}
+	return level.String()
+}
+
+// DurationEncoding defines how duration fields are rendered
+type DurationEncoding string
+
+const (
+	DurationString DurationEncoding = "string" // Go duration string, e.g. "1.5s" (console default)
+	DurationSecs   DurationEncoding = "secs"   // Floating-point seconds, e.g. 1.5
+	DurationMillis DurationEncoding = "millis" // Integer milliseconds, e.g. 1500
+	DurationNanos  DurationEncoding = "nanos"  // Integer nanoseconds (JSON default)
+)
+
+// encodeDuration converts d according to enc
+func encodeDuration(d time.Duration, enc DurationEncoding) interface{} {
+	switch enc {
+	case DurationString:
+		return d.String()
+	case DurationSecs:
+		return d.Seconds()
+	case DurationMillis:
+		return d.Milliseconds()
+	default:
+		return int64(d)
+	}
+}
+
+// encodeDurationField applies enc to duration and duration slice fields
+// Values of other field types are returned unchanged
+func encodeDurationField(field Field, enc DurationEncoding) interface{} {
+	switch field.Type {
+	case DurationType:
+		if d, ok := field.Value.(time.Duration); ok {
+			return encodeDuration(d, enc)
+		}
+	case DurationsType:
+		if ds, ok := field.Value.([]time.Duration); ok {
+			vals := make([]interface{}, len(ds))
+			for i, d := range ds {
+				vals[i] = encodeDuration(d, enc)
+			}
+			return vals
+		}
+	}
+	return field.Value
+}
+
+// reservedKeyPrefix is prepended to event fields named like the keys the JSON and
+// binary formatters write themselves, e.g. a "level" field is written as "fields.level"
+const reservedKeyPrefix = "fields."
+
+// fieldKey returns the key an event field is written under at the top level
+func fieldKey(key string) string {
+	switch key {
+	case "timestamp", "level", "caller", "message", "context":
+		return reservedKeyPrefix + key
+	}
+	return key
+}
+
+// topLevelFields returns event fields under the keys they are written with at the
+// top level. Repeated keys keep the last field, so the output never repeats a key
+func topLevelFields(fields []Field) []Field {
+	renamed, copied := fields, false
+	for i, field := range fields {
+		key := fieldKey(field.Key)
+		if key == field.Key {
+			continue
+		}
+		if !copied {
+			renamed, copied = append([]Field(nil), fields...), true
+		}
+		renamed[i].Key = key
+	}
+	_, renamed = applyDuplicateKeyPolicy(nil, renamed, DuplicateKeepLast)
+	return renamed
+}
+
+// cloudLoggingKeyPrefix marks Google Cloud Logging special fields, such as the
+// logging.googleapis.com/trace field added by requestid.Middleware
+const cloudLoggingKeyPrefix = "logging.googleapis.com/"
+
+// countWithoutPrefix returns the number of fields whose key does not start with prefix
+func countWithoutPrefix(fields []Field, prefix string) int {
+	n := 0
+	for _, field := range fields {
+		if !strings.HasPrefix(field.Key, prefix) {
+			n++
+		}
+	}
+	return n
+}
+
 // JSONFormatter formats log entries as JSON
+// Control characters are escaped and invalid UTF-8 is replaced by U+FFFD, so values
+// cannot break out of their string or the line
 type JSONFormatter struct {
-	TimestampFormat string
-	AddCaller       bool
+	TimestampFormat   string
+	TimestampEncoding TimestampEncoding
+	TimeEncoding      TimestampEncoding // Time fields; empty writes RFC 3339 strings with nanoseconds
+	DurationEncoding  DurationEncoding
+	LevelEncoding     LevelEncoding
+	LevelLabels       map[Level]string // Custom level names, e.g. {WarnLevel: "WARNING"}
+	AddCaller         bool
+
+	// Pretty emits multi-line indented JSON, intended for local development
+	Pretty bool
+	// UseColor colors object keys with KeyColor (cyan when empty)
+	UseColor bool
+	KeyColor string
 }
 
-// Format implements the Formatter interface for JSON output
-func (f *JSONFormatter) Format(entry Entry) ([]byte, error) {
-	m := make(map[string]interface{})
+// fieldValue returns the JSON value for a field
+// Groups become maps; Format writes them as objects in field order instead
+func (f *JSONFormatter) fieldValue(field Field) interface{} {
+	if field.Type == GroupType {
+		members := groupFields(field)
+		m := make(map[string]interface{}, len(members))
+		for _, member := range members {
+			m[member.Key] = f.fieldValue(member)
+		}
+		return m
+	}
+	if field.Type == TimeType && f.TimeEncoding != "" {
+		if t, ok := field.Value.(time.Time); ok {
+			return encodeTime(t, f.TimeEncoding, time.RFC3339Nano)
+		}
+	}
+	enc := f.DurationEncoding
+	if enc == "" {
+		enc = DurationNanos
+	}
+	return encodeDurationField(field, enc)
+}
 
-	// Add timestamp
+// encodeTimestamp returns the JSON value for the entry timestamp
+func (f *JSONFormatter) encodeTimestamp(t time.Time) interface{} {
 	timestampFormat := f.TimestampFormat
 	if timestampFormat == "" {
 		timestampFormat = time.RFC3339
 	}
-	m["timestamp"] = entry.Time.Format(timestampFormat)
+	return encodeTime(t, f.TimestampEncoding, timestampFormat)
+}
+
+// Format implements the Formatter interface for JSON output
+// Keys are written in a stable order: timestamp, level, caller, message,
+// then event fields in insertion order, then the context object
+// Event fields named like these keys are written as "fields.<key>", and a repeated
+// key is written once with the last field's value
+// Context fields with a logging.googleapis.com/ key are written at the top level
+func (f *JSONFormatter) Format(entry Entry) ([]byte, error) {
+	enc := &jsonEncoder{pretty: f.Pretty}
+	if f.UseColor {
+		enc.keyColor = f.KeyColor
+		if enc.keyColor == "" {
+			enc.keyColor = colorCyan
+		}
+	}
+	enc.openObject()
+
+	// Add timestamp
+	if err := enc.field("timestamp", f.encodeTimestamp(entry.Time)); err != nil {
+		return nil, err
+	}
 
 	// Add level
-	m["level"] = entry.Level.String()
+	if err := enc.field("level", encodeLevel(entry.Level, f.LevelEncoding, f.LevelLabels)); err != nil {
+		return nil, err
+	}
+
+	// Add caller info
+	if f.AddCaller && entry.Caller.File != "" {
+		caller := fmt.Sprintf("%s:%d", entry.Caller.File, entry.Caller.Line)
+		if err := enc.field("caller", caller); err != nil {
+			return nil, err
+		}
+	}
 
 	// Add message
 	if entry.Message != "" {
-		m["message"] = entry.Message
+		if err := enc.field("message", entry.Message); err != nil {
+			return nil, err
+		}
 	}
 
-	// Add caller info
-	if f.AddCaller {
-		m["caller"] = fmt.Sprintf("%s:%d", entry.Caller.File, entry.Caller.Line)
+	// Add event-specific fields, renaming those that collide with the keys above
+	fields := topLevelFields(entry.Fields)
+	for _, field := range fields {
+		if err := f.writeField(enc, field); err != nil {
+			return nil, err
+		}
 	}
 
-	// Add event-specific fields
-	for _, field := range entry.Fields {
-		m[field.Key] = field.Value
+	// Cloud Logging only reads its special fields at the top level, where an event
+	// field with the same key replaces them
+	_, contextFields := applyDuplicateKeyPolicy(nil, entry.ContextFields, DuplicateKeepLast)
+	for _, field := range contextFields {
+		if strings.HasPrefix(field.Key, cloudLoggingKeyPrefix) && !containsKey(fields, field.Key) {
+			if err := f.writeField(enc, field); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	// Add context fields under "context" key
-	if len(entry.ContextFields) > 0 {
-		contextData := make(map[string]interface{})
-		for _, field := range entry.ContextFields {
-			contextData[field.Key] = field.Value
+	if n := countWithoutPrefix(contextFields, cloudLoggingKeyPrefix); n > 0 {
+		if err := enc.key("context"); err != nil {
+			return nil, err
 		}
-		m["context"] = contextData
+		enc.openObject()
+		for _, field := range contextFields {
+			if strings.HasPrefix(field.Key, cloudLoggingKeyPrefix) {
+				continue
+			}
+			if err := f.writeField(enc, field); err != nil {
+				return nil, err
+			}
+		}
+		enc.closeObject()
 	}
 
-	// Marshal to JSON
-	data, err := json.Marshal(m)
-	if err != nil {
-		return nil, err
-	}
+	enc.closeObject()
 
 	// Add newline
-	data = append(data, '\n')
-	return data, nil
+	enc.buf.WriteByte('\n')
+	return enc.buf.Bytes(), nil
+}
+
+// writeField writes a field, nesting groups as objects
+func (f *JSONFormatter) writeField(enc *jsonEncoder, field Field) error {
+	if field.Type != GroupType {
+		// A value that cannot be encoded is replaced so the rest of the entry is kept
+		encoded, err := enc.marshal(f.fieldValue(field))
+		if err != nil {
+			encoded, _ = enc.marshal(badValue(field.Key, err))
+		}
+		return enc.rawField(field.Key, encoded)
+	}
+	if err := enc.key(field.Key); err != nil {
+		return err
+	}
+	enc.openObject()
+	for _, member := range groupFields(field) {
+		if err := f.writeField(enc, member); err != nil {
+			return err
+		}
+	}
+	enc.closeObject()
+	return nil
 }
 
 // ConsoleFormatter formats log entries for console output with colors
+// Control characters in messages and keys are escaped (\n, \x1b) and values containing
+// them are quoted, so user input cannot forge log lines or inject terminal escapes
 type ConsoleFormatter struct {
-	TimestampFormat string
-	AddCaller       bool
-	UseColor        bool
-	ColorConfig     ColorConfig
+	TimestampFormat  string
+	DurationEncoding DurationEncoding
+	AddCaller        bool
+	UseColor         bool
+	ColorConfig      ColorConfig
+
+	// MultilineValues renders string values containing newlines (stack traces,
+	// SQL queries, diffs) as indented blocks below the log line
+	MultilineValues bool
 }
 
+// multilineIndent is the indentation used for multi-line value blocks
+const multilineIndent = "    "
+
 // Format implements the Formatter interface for console output
 func (f *ConsoleFormatter) Format(entry Entry) ([]byte, error) {
 	var output string
@@ -111,16 +410,7 @@ func (f *ConsoleFormatter) Format(entry Entry) ([]byte, error) {
 	// Get color for level
 	levelColor := ""
 	if f.UseColor {
-		switch entry.Level {
-		case DebugLevel:
-			levelColor = f.ColorConfig.Debug
-		case InfoLevel:
-			levelColor = f.ColorConfig.Info
-		case WarnLevel:
-			levelColor = f.ColorConfig.Warn
-		case ErrorLevel:
-			levelColor = f.ColorConfig.Error
-		}
+		levelColor = entry.Level.color(f.ColorConfig)
 	}
 
 	// Format timestamp
@@ -132,36 +422,204 @@ func (f *ConsoleFormatter) Format(entry Entry) ([]byte, error) {
 
 	// Build output string
 	if f.UseColor {
-		output = fmt.Sprintf("%s[%s] %s%-5s%s", colorCyan, timestamp, levelColor, entry.Level.String(), f.ColorConfig.Reset)
+		timestampColor := f.ColorConfig.Timestamp
+		if timestampColor == "" {
+			timestampColor = colorCyan
+		}
+		output = fmt.Sprintf("%s[%s] %s%-5s%s", timestampColor, timestamp, levelColor, entry.Level.String(), f.ColorConfig.Reset)
 	} else {
 		output = fmt.Sprintf("[%s] %-5s", timestamp, entry.Level.String())
 	}
 
 	// Add caller info
-	if f.AddCaller {
-		output += fmt.Sprintf(" %s:%d", entry.Caller.File, entry.Caller.Line)
+	if f.AddCaller && entry.Caller.File != "" {
+		if f.UseColor && f.ColorConfig.Caller != "" {
+			output += fmt.Sprintf(" %s%s:%d%s", f.ColorConfig.Caller, entry.Caller.File, entry.Caller.Line, f.ColorConfig.Reset)
+		} else {
+			output += fmt.Sprintf(" %s:%d", entry.Caller.File, entry.Caller.Line)
+		}
 	}
 
 	// Add message
 	if entry.Message != "" {
-		output += " " + entry.Message
+		output += " " + f.paint(f.ColorConfig.Message, sanitizeText(entry.Message))
+	}
+
+	// Multi-line values are pulled out of the line and rendered below it
+	var blocks []Field
+	fields := flattenGroups(entry.Fields)
+	contextFields := flattenGroups(entry.ContextFields)
+	if f.MultilineValues {
+		fields, blocks = splitMultiline(fields, blocks)
+		contextFields, blocks = splitMultiline(contextFields, blocks)
 	}
 
 	// Add event-specific fields first
-	if len(entry.Fields) > 0 {
-		for _, field := range entry.Fields {
-			output += fmt.Sprintf(" %s=%v", field.Key, field.Value)
-		}
+	for _, field := range fields {
+		output += " " + f.paint(f.ColorConfig.Key, sanitizeText(field.Key)) + "=" + f.paint(f.ColorConfig.Value, f.fieldValue(field))
 	}
 
 	// Add context fields (separated with | symbol)
-	if len(entry.ContextFields) > 0 {
-		output += " |"
-		for _, field := range entry.ContextFields {
-			output += fmt.Sprintf(" %s=%v", field.Key, field.Value)
+	if len(contextFields) > 0 {
+		if f.UseColor && f.ColorConfig.Context != "" {
+			block := " |"
+			for _, field := range contextFields {
+				block += fmt.Sprintf(" %s=%s", sanitizeText(field.Key), f.fieldValue(field))
+			}
+			output += f.paint(f.ColorConfig.Context, block)
+		} else {
+			output += " |"
+			for _, field := range contextFields {
+				output += " " + f.paint(f.ColorConfig.Key, sanitizeText(field.Key)) + "=" + f.paint(f.ColorConfig.Value, f.fieldValue(field))
+			}
 		}
 	}
 
 	output += "\n"
+
+	// Add multi-line blocks, one indented section per field
+	for _, field := range blocks {
+		output += multilineIndent + f.paint(f.ColorConfig.Key, sanitizeText(field.Key)) + ":\n"
+		value := strings.TrimRight(field.Value.(string), "\n")
+		for _, line := range strings.Split(value, "\n") {
+			output += multilineIndent + multilineIndent + f.paint(f.ColorConfig.Value, sanitizeText(strings.TrimRight(line, "\r"))) + "\n"
+		}
+	}
+
 	return []byte(output), nil
 }
+
+// splitMultiline moves string fields containing newlines from fields to blocks
+func splitMultiline(fields, blocks []Field) ([]Field, []Field) {
+	inline := make([]Field, 0, len(fields))
+	for _, field := range fields {
+		if s, ok := field.Value.(string); ok && strings.Contains(s, "\n") {
+			blocks = append(blocks, field)
+			continue
+		}
+		inline = append(inline, field)
+	}
+	return inline, blocks
+}
+
+// paint colors s when colors are enabled and color is set
+func (f *ConsoleFormatter) paint(color, s string) string {
+	if !f.UseColor {
+		return s
+	}
+	return f.ColorConfig.paint(color, s)
+}
+
+// fieldValue renders a field value for console output, quoted logfmt-style when needed
+func (f *ConsoleFormatter) fieldValue(field Field) string {
+	return quoteValue(f.rawValue(field))
+}
+
+// rawValue renders a field value for console output without quoting
+// Slice fields are comma-joined so they stay on a single token
+func (f *ConsoleFormatter) rawValue(field Field) string {
+	enc := f.DurationEncoding
+	if enc == "" {
+		enc = DurationString
+	}
+
+	switch field.Type {
+	case StringsType, ErrorsType:
+		if vals, ok := field.Value.([]string); ok {
+			return strings.Join(vals, ",")
+		}
+	case DurationType:
+		return fmt.Sprintf("%v", encodeDurationField(field, enc))
+	case IntsType, Float64sType, BoolsType:
+		return joinSlice(field.Value)
+	case DurationsType:
+		return joinSlice(encodeDurationField(field, enc))
+	case AnyType:
+		// fmt recovers from panicking String methods but recurses forever on cycles
+		if err := checkCycles(field.Value); err != nil {
+			return badValue(field.Key, err)
+		}
+	}
+	return fmt.Sprintf("%v", field.Value)
+}
+
+// joinSlice formats each element of a slice and joins them with commas
+func joinSlice(value interface{}) string {
+	var parts []string
+	switch vals := value.(type) {
+	case []int:
+		for _, v := range vals {
+			parts = append(parts, fmt.Sprintf("%d", v))
+		}
+	case []float64:
+		for _, v := range vals {
+			parts = append(parts, fmt.Sprintf("%v", v))
+		}
+	case []bool:
+		for _, v := range vals {
+			parts = append(parts, fmt.Sprintf("%t", v))
+		}
+	case []interface{}:
+		for _, v := range vals {
+			parts = append(parts, fmt.Sprintf("%v", v))
+		}
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+	return strings.Join(parts, ",")
+}
+
+// quoteValue quotes s logfmt-style when it would otherwise be ambiguous
+// Empty values and values containing spaces, '=', quotes, control
+// characters or invalid UTF-8 are wrapped in double quotes and escaped
+func quoteValue(s string) string {
+	if s == "" {
+		return `""`
+	}
+	if !utf8.ValidString(s) {
+		return strconv.Quote(s)
+	}
+	for _, r := range s {
+		if r <= ' ' || r == '=' || r == '"' || !unicode.IsPrint(r) {
+			return strconv.Quote(s)
+		}
+	}
+	return s
+}
+
+// sanitizeText escapes control characters (except tab) and replaces invalid UTF-8,
+// so user input in messages and keys cannot forge log lines or inject terminal
+// escape sequences
+func sanitizeText(s string) string {
+	clean := true
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if (r == utf8.RuneError && size == 1) || (r != '\t' && unicode.IsControl(r)) {
+			clean = false
+			break
+		}
+		i += size
+	}
+	if clean {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		switch {
+		case r == utf8.RuneError && size == 1:
+			b.WriteRune(utf8.RuneError)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r != '\t' && unicode.IsControl(r):
+			fmt.Fprintf(&b, `\x%02x`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
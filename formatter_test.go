@@ -0,0 +1,65 @@
+package logpy
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONFormatterWritesEachKeyOnce(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  []Field
+		context []Field
+		want    string
+	}{
+		{"repeated event field",
+			[]Field{String("a", "1"), String("b", "2"), String("a", "3")}, nil,
+			`"message":"msg","b":"2","a":"3"}`},
+		{"reserved key",
+			[]Field{String("level", "x")}, nil,
+			`"message":"msg","fields.level":"x"}`},
+		{"reserved key and its renamed form",
+			[]Field{String("fields.level", "x"), String("level", "y")}, nil,
+			`"message":"msg","fields.level":"y"}`},
+		{"repeated context field",
+			nil, []Field{String("a", "1"), String("a", "2")},
+			`"message":"msg","context":{"a":"2"}}`},
+		{"cloud logging field repeated by event field",
+			[]Field{String(cloudLoggingKeyPrefix+"trace", "event")}, []Field{String(cloudLoggingKeyPrefix+"trace", "context")},
+			`"message":"msg","logging.googleapis.com/trace":"event"}`},
+	}
+
+	f := &JSONFormatter{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := Entry{Time: time.Unix(0, 0).UTC(), Level: InfoLevel, Message: "msg", Fields: tt.fields, ContextFields: tt.context}
+			out, err := f.Format(entry)
+			if err != nil {
+				t.Fatalf("Format: %v", err)
+			}
+			if got := strings.TrimSpace(string(out)); !strings.HasSuffix(got, tt.want) {
+				t.Errorf("Format = %s, want suffix %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBinaryFormatterWritesEachKeyOnce(t *testing.T) {
+	entry := Entry{Time: time.Unix(0, 0).UTC(), Level: InfoLevel, Message: "msg",
+		Fields: []Field{String("a", "1"), String("a", "2")}, ContextFields: []Field{String("b", "1"), String("b", "2")}}
+	out, err := (&BinaryFormatter{}).Format(entry)
+	if err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	// A MessagePack fixmap header holds its entry count in the low four bits
+	if size := out[0] & 0x0f; size != 5 {
+		t.Errorf("map size = %d, want 5", size)
+	}
+	if n := strings.Count(string(out), "\xa1a"); n != 1 {
+		t.Errorf("key a written %d times, want 1", n)
+	}
+	if n := strings.Count(string(out), "\xa1b"); n != 1 {
+		t.Errorf("key b written %d times, want 1", n)
+	}
+}
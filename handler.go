@@ -1,9 +1,16 @@
 package logpy
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"reflect"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"gopkg.in/natefinch/lumberjack.v2"
 )
@@ -18,17 +25,25 @@ type Handler interface {
 	WithFields(fields []Field) Handler
 }
 
+// Flusher is implemented by handlers that buffer entries and can flush them
+type Flusher interface {
+	Flush() error
+}
+
 // baseHandler provides common functionality for all handlers
 type baseHandler struct {
-	level     Level
-	formatter Formatter
-	writer    io.Writer
-	mu        sync.Mutex
+	level      Level
+	formatter  Formatter
+	writer     io.Writer
+	mu         sync.Mutex
+	syncPolicy SyncPolicy
+	syncStop   chan struct{} // Stops the SyncInterval goroutine
+	stats      handlerStats
 }
 
 // Enabled implements the Handler interface
 func (h *baseHandler) Enabled(level Level) bool {
-	return level >= h.level
+	return level.AtLeast(h.level)
 }
 
 // Handle implements the Handler interface
@@ -37,17 +52,123 @@ func (h *baseHandler) Handle(entry Entry) error {
 		return nil
 	}
 
+	// Evaluate lazy fields now that the entry is known to be handled
+	entry = entry.resolveLazy()
+
 	// Format the entry
 	data, err := h.formatter.Format(entry)
 	if err != nil {
 		return err
 	}
+	return h.write(entry.Level, data)
+}
 
-	// Write to output (thread-safe)
+// write writes a formatted entry with a single Write call (thread-safe), so lines
+// never interleave with other writers sharing the destination
+func (h *baseHandler) write(level Level, data []byte) error {
 	h.mu.Lock()
 	defer h.mu.Unlock()
-	_, err = h.writer.Write(data)
-	return err
+	n, err := h.writer.Write(data)
+	metrics.bytesWritten.Add(uint64(n))
+	h.stats.bytes.Add(uint64(n))
+	if err != nil {
+		h.stats.writeErrors.Add(1)
+		return err
+	}
+	h.stats.entries.Add(1)
+	return h.syncAfterWrite(level)
+}
+
+// base returns the handler itself; it is promoted to the built-in handlers so
+// MultiHandler can reuse their encoding of an entry
+func (h *baseHandler) base() *baseHandler {
+	return h
+}
+
+// formattedHandler is implemented by the handlers built on baseHandler
+type formattedHandler interface {
+	base() *baseHandler
+}
+
+// encodedEntry is an entry encoded by one formatter configuration
+type encodedEntry struct {
+	key  interface{}
+	data []byte
+	err  error
+}
+
+// handleEncoded sends entry to handler, reusing the encoding of an earlier built-in
+// handler with the same formatter configuration from cache
+func handleEncoded(handler Handler, entry Entry, cache *[]encodedEntry) error {
+	fh, ok := handler.(formattedHandler)
+	if !ok {
+		return handler.Handle(entry)
+	}
+	b := fh.base()
+	key, ok := formatterKey(b.formatter)
+	if !ok {
+		return handler.Handle(entry)
+	}
+	if !b.Enabled(entry.Level) {
+		return nil
+	}
+
+	for _, encoded := range *cache {
+		if encoded.key == key {
+			if encoded.err != nil {
+				return encoded.err
+			}
+			return b.write(entry.Level, encoded.data)
+		}
+	}
+	data, err := b.formatter.Format(entry)
+	*cache = append(*cache, encodedEntry{key: key, data: data, err: err})
+	if err != nil {
+		return err
+	}
+	return b.write(entry.Level, data)
+}
+
+// formatterKey returns a comparable key that is equal for formatters producing the
+// same output: the configuration of the built-in formatters, otherwise the formatter itself
+func formatterKey(f Formatter) (interface{}, bool) {
+	var key interface{} = f
+	switch f := f.(type) {
+	case *JSONFormatter:
+		key = jsonFormatterKey{
+			timestampFormat:   f.TimestampFormat,
+			timestampEncoding: f.TimestampEncoding,
+			timeEncoding:      f.TimeEncoding,
+			durationEncoding:  f.DurationEncoding,
+			levelEncoding:     f.LevelEncoding,
+			levelLabels:       reflect.ValueOf(f.LevelLabels).Pointer(),
+			addCaller:         f.AddCaller,
+			pretty:            f.Pretty,
+			useColor:          f.UseColor,
+			keyColor:          f.KeyColor,
+		}
+	case *ConsoleFormatter:
+		key = *f
+	}
+	if key == nil || !reflect.TypeOf(key).Comparable() {
+		return nil, false
+	}
+	return key, true
+}
+
+// jsonFormatterKey is the configuration of a JSONFormatter as a comparable value;
+// LevelLabels maps are compared by identity
+type jsonFormatterKey struct {
+	timestampFormat   string
+	timestampEncoding TimestampEncoding
+	timeEncoding      TimestampEncoding
+	durationEncoding  DurationEncoding
+	levelEncoding     LevelEncoding
+	levelLabels       uintptr
+	addCaller         bool
+	pretty            bool
+	useColor          bool
+	keyColor          string
 }
 
 // WithFields implements the Handler interface
@@ -57,6 +178,43 @@ func (h *baseHandler) WithFields(fields []Field) Handler {
 	return h
 }
 
+// Flush implements the Flusher interface
+// Writes are unbuffered, so this only flushes writers that buffer themselves (e.g. bufio.Writer)
+func (h *baseHandler) Flush() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if f, ok := h.writer.(Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// Close flushes the handler and stops background work
+// The underlying writer is left open since it is owned by the caller
+func (h *baseHandler) Close() error {
+	h.stopSync()
+	return h.Flush()
+}
+
+// SetTimestampFormat changes the timestamp layout used by the handler's formatter
+// It applies to the built-in JSON and console formatters and should be called before logging
+func (h *baseHandler) SetTimestampFormat(layout string) {
+	switch f := h.formatter.(type) {
+	case *JSONFormatter:
+		f.TimestampFormat = layout
+	case *ConsoleFormatter:
+		f.TimestampFormat = layout
+	}
+}
+
+// SetFormatter replaces the handler's formatter, e.g. with a custom Formatter implementation
+// A nil formatter is ignored; like SetTimestampFormat it should be called before logging
+func (h *baseHandler) SetFormatter(f Formatter) {
+	if f != nil {
+		h.formatter = f
+	}
+}
+
 // ConsoleHandler is a handler that writes to console with optional colors
 type ConsoleHandler struct {
 	*baseHandler
@@ -64,36 +222,33 @@ type ConsoleHandler struct {
 
 // NewConsoleHandler creates a new console handler
 func NewConsoleHandler(level Level, useColor bool) *ConsoleHandler {
-	formatter := &ConsoleFormatter{
-		TimestampFormat: "2006-01-02 15:04:05",
-		AddCaller:       true,
-		UseColor:        useColor,
-		ColorConfig:     DefaultColorConfig(),
-	}
-
-	return &ConsoleHandler{
-		baseHandler: &baseHandler{
-			level:     level,
-			formatter: formatter,
-			writer:    os.Stdout,
-		},
-	}
+	return NewConsoleHandlerWithConfig(level, useColor, DefaultColorConfig())
 }
 
 // NewConsoleHandlerWithConfig creates a console handler with custom configuration
 func NewConsoleHandlerWithConfig(level Level, useColor bool, colorConfig ColorConfig) *ConsoleHandler {
 	formatter := &ConsoleFormatter{
-		TimestampFormat: "2006-01-02 15:04:05",
+		TimestampFormat: defaultConsoleTimestampFormat,
 		AddCaller:       true,
 		UseColor:        useColor,
 		ColorConfig:     colorConfig,
 	}
 
+	return newConsoleHandler(level, formatter)
+}
+
+// NewConsoleHandlerWithFormatter creates a console handler that writes entries formatted by f to stdout
+func NewConsoleHandlerWithFormatter(level Level, f Formatter) *ConsoleHandler {
+	return newConsoleHandler(level, f)
+}
+
+// newConsoleHandler creates a console handler that writes to stdout with the given formatter
+func newConsoleHandler(level Level, formatter Formatter) *ConsoleHandler {
 	return &ConsoleHandler{
 		baseHandler: &baseHandler{
 			level:     level,
 			formatter: formatter,
-			writer:    os.Stdout,
+			writer:    consoleWriter(os.Stdout),
 		},
 	}
 }
@@ -106,10 +261,15 @@ type JSONHandler struct {
 // NewJSONHandler creates a new JSON handler that writes to the specified writer
 func NewJSONHandler(writer io.Writer, level Level) *JSONHandler {
 	formatter := &JSONFormatter{
-		TimestampFormat: "2006-01-02T15:04:05.000Z07:00", // ISO 8601
+		TimestampFormat: defaultJSONTimestampFormat,
 		AddCaller:       true,
 	}
 
+	return newJSONHandler(writer, level, formatter)
+}
+
+// newJSONHandler creates a JSON handler with the given formatter
+func newJSONHandler(writer io.Writer, level Level, formatter Formatter) *JSONHandler {
 	return &JSONHandler{
 		baseHandler: &baseHandler{
 			level:     level,
@@ -119,14 +279,54 @@ func NewJSONHandler(writer io.Writer, level Level) *JSONHandler {
 	}
 }
 
+// WriterHandler is a handler that writes entries formatted by any Formatter to an io.Writer
+type WriterHandler struct {
+	*baseHandler
+}
+
+// NewWriterHandler creates a handler writing to w (a pipe, buffer or custom sink) with
+// formatter f, e.g. &ConsoleFormatter{} for plain text; nil f writes JSON
+// Writers that implement Flusher are flushed by Flush; w is not closed
+func NewWriterHandler(w io.Writer, level Level, f Formatter) *WriterHandler {
+	if f == nil {
+		f = &JSONFormatter{
+			TimestampFormat: defaultJSONTimestampFormat,
+			AddCaller:       true,
+		}
+	}
+	return &WriterHandler{
+		baseHandler: &baseHandler{
+			level:     level,
+			formatter: f,
+			writer:    w,
+		},
+	}
+}
+
 // FileHandler is a handler that writes to a file with rotation support
 type FileHandler struct {
 	*baseHandler
 	rotator *lumberjack.Logger
+	tracker *rotationTracker
 }
 
 // NewFileHandler creates a new file handler with rotation support
 func NewFileHandler(filename string, level Level, maxSize, maxBackups, maxAge int, compress bool) *FileHandler {
+	formatter := &JSONFormatter{
+		TimestampFormat: defaultJSONTimestampFormat,
+		AddCaller:       true,
+	}
+
+	return newFileHandler(filename, level, maxSize, maxBackups, maxAge, compress, formatter)
+}
+
+// NewFileHandlerWithFormatter creates a size-rotating file handler that writes entries formatted by f
+func NewFileHandlerWithFormatter(filename string, level Level, maxSize, maxBackups, maxAge int, compress bool, f Formatter) *FileHandler {
+	return newFileHandler(filename, level, maxSize, maxBackups, maxAge, compress, f)
+}
+
+// newFileHandler creates a size-rotating file handler with the given formatter
+func newFileHandler(filename string, level Level, maxSize, maxBackups, maxAge int, compress bool, formatter Formatter) *FileHandler {
 	rotator := &lumberjack.Logger{
 		Filename:   filename,
 		MaxSize:    maxSize,    // MB
@@ -136,40 +336,193 @@ func NewFileHandler(filename string, level Level, maxSize, maxBackups, maxAge in
 		LocalTime:  true,       // Use local time for filenames
 	}
 
-	formatter := &JSONFormatter{
-		TimestampFormat: "2006-01-02T15:04:05.000Z07:00",
-		AddCaller:       true,
-	}
-
-	return &FileHandler{
+	h := &FileHandler{
 		baseHandler: &baseHandler{
 			level:     level,
 			formatter: formatter,
-			writer:    rotator,
 		},
 		rotator: rotator,
 	}
+	h.tracker = &rotationTracker{Logger: rotator, stats: &h.stats, base: h.baseHandler}
+	h.writer = h.tracker
+	return h
+}
+
+// Stats returns the handler's counters and the current file, for health endpoints
+func (h *FileHandler) Stats() FileStats {
+	stats := h.stats.snapshot()
+	stats.Path = h.rotator.Filename
+	stats.Size = h.tracker.currentSize()
+	return stats
+}
+
+// rotationTracker wraps lumberjack to count its rotations and track the size of the
+// current file. It applies lumberjack's rule itself, calling Rotate when a write would
+// make the file exceed MaxSize, so every rotation is counted and the size matches the
+// file. Only other processes appending to the same file can make them drift.
+// New files start with the formatter's header, if any
+type rotationTracker struct {
+	*lumberjack.Logger
+	stats  *handlerStats
+	base   *baseHandler // Supplies the formatter's header
+	size   atomic.Int64
+	opened atomic.Bool // The size has been read from the file since it was last closed
+}
+
+// Write implements io.Writer
+func (t *rotationTracker) Write(p []byte) (int, error) {
+	maxSize := int64(t.MaxSize) * 1024 * 1024
+	if maxSize == 0 {
+		maxSize = 100 * 1024 * 1024 // lumberjack's default
+	}
+	size, n := t.size.Load(), int64(len(p))
+	rotate := false
+	if !t.opened.Load() {
+		// lumberjack rotates an existing file on open when the write reaches MaxSize
+		size = 0
+		if info, err := os.Stat(t.Filename); err == nil {
+			size = info.Size()
+			rotate = size+n >= maxSize && n <= maxSize
+		}
+		t.opened.Store(true)
+	} else {
+		rotate = size+n > maxSize && n <= maxSize
+	}
+	if rotate {
+		if err := t.Logger.Rotate(); err != nil {
+			t.opened.Store(false)
+			return 0, err
+		}
+		t.rotated()
+		size = 0
+	}
+
+	if header := formatterHeader(t.base.formatter); size == 0 && len(header) > 0 {
+		hn, err := t.Logger.Write(header)
+		size += int64(hn)
+		if err != nil {
+			t.size.Store(size)
+			return 0, err
+		}
+	}
+
+	written, err := t.Logger.Write(p)
+	t.size.Store(size + int64(written))
+	return written, err
+}
+
+// Close closes the current file; the next write reads its size again
+func (t *rotationTracker) Close() error {
+	t.opened.Store(false)
+	return t.Logger.Close()
+}
+
+// Sync implements syncer so SyncPolicy applies to size-rotated files. lumberjack keeps
+// its file private, so the current file is opened again and synced; fsync flushes the
+// data written through any descriptor of the file
+func (t *rotationTracker) Sync() error {
+	f, err := os.OpenFile(t.Filename, os.O_WRONLY, 0)
+	if os.IsNotExist(err) {
+		return nil // Nothing written yet
+	}
+	if err != nil {
+		return err
+	}
+	return errors.Join(f.Sync(), f.Close())
+}
+
+// rotated counts a rotation
+func (t *rotationTracker) rotated() {
+	t.stats.rotations.Add(1)
+	metrics.rotations.Add(1)
+}
+
+// currentSize returns the size of the current file
+func (t *rotationTracker) currentSize() int64 {
+	if t.opened.Load() {
+		return t.size.Load()
+	}
+	if info, err := os.Stat(t.Filename); err == nil {
+		return info.Size()
+	}
+	return 0
+}
+
+// SetPermissions creates the log directory with dirMode and the log file with
+// fileMode if they do not exist yet; lumberjack keeps the file mode across rotations.
+// A zero value uses the default (0644 for files, 0755 for directories)
+func (h *FileHandler) SetPermissions(fileMode, dirMode os.FileMode) error {
+	if fileMode == 0 {
+		fileMode = defaultFileMode
+	}
+	if dirMode == 0 {
+		dirMode = defaultDirMode
+	}
+
+	if err := os.MkdirAll(filepath.Dir(h.rotator.Filename), dirMode); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	f, err := os.OpenFile(h.rotator.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, fileMode)
+	if err != nil {
+		return fmt.Errorf("failed to create log file: %w", err)
+	}
+	return f.Close()
 }
 
 // Close closes the file handler and flushes any buffered data
 func (h *FileHandler) Close() error {
-	return h.rotator.Close()
+	h.stopSync()
+	return h.tracker.Close()
+}
+
+// ErrHandlerTimeout is returned when a handler does not finish within the dispatch timeout
+var ErrHandlerTimeout = errors.New("logpy: handler timed out")
+
+// ErrorPolicy controls how MultiHandler reports failures of its children
+type ErrorPolicy string
+
+const (
+	ErrorPolicyLast     ErrorPolicy = "last"      // Return the last child error (default)
+	ErrorPolicyJoin     ErrorPolicy = "join"      // Return all child errors joined with errors.Join
+	ErrorPolicyFailFast ErrorPolicy = "fail-fast" // Stop at the first failing child and return its error
+)
+
+// HandlerError identifies the child handler that failed inside a MultiHandler
+// Use errors.As in an ErrorHandler to find out which destination failed
+type HandlerError struct {
+	Index   int     // Position of the handler in NewMultiHandler's arguments
+	Handler Handler // The handler that failed
+	Err     error
+}
+
+// Error implements the error interface
+func (e *HandlerError) Error() string {
+	return fmt.Sprintf("handler %d (%T): %v", e.Index, e.Handler, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *HandlerError) Unwrap() error {
+	return e.Err
 }
 
 // MultiHandler sends log entries to multiple handlers
 type MultiHandler struct {
-	handlers []Handler
-	level    Level
+	handlers    []Handler
+	level       Level
+	parallel    bool          // Dispatch to children concurrently
+	timeout     time.Duration // Per-child wait in parallel mode (0 = wait for all)
+	errorPolicy ErrorPolicy
 }
 
 // NewMultiHandler creates a handler that writes to multiple handlers
 func NewMultiHandler(handlers ...Handler) *MultiHandler {
 	// Find the minimum level among all handlers
-	minLevel := ErrorLevel
+	levels := Levels()
+	minLevel := levels[len(levels)-1]
 	for _, h := range handlers {
-		for l := DebugLevel; l <= ErrorLevel; l++ {
+		for _, l := range levels {
 			if h.Enabled(l) {
-				if l < minLevel {
+				if !l.AtLeast(minLevel) {
 					minLevel = l
 				}
 				break
@@ -178,8 +531,9 @@ func NewMultiHandler(handlers ...Handler) *MultiHandler {
 	}
 
 	return &MultiHandler{
-		handlers: handlers,
-		level:    minLevel,
+		handlers:    handlers,
+		level:       minLevel,
+		errorPolicy: ErrorPolicyLast,
 	}
 }
 
@@ -196,13 +550,147 @@ func (h *MultiHandler) Enabled(level Level) bool {
 
 // Handle implements the Handler interface
 func (h *MultiHandler) Handle(entry Entry) error {
-	var lastErr error
+	// Evaluate lazy fields once so every child sees the same value
+	entry = entry.resolveLazy()
+
+	if h.parallel {
+		return h.handleParallel(entry)
+	}
+
+	// Children with the same formatter configuration share one encoding of the entry
+	var cache []encodedEntry
+	errs := make([]error, len(h.handlers))
+	for i, handler := range h.handlers {
+		if err := handleEncoded(handler, entry, &cache); err != nil {
+			errs[i] = h.wrapError(i, err)
+			if h.errorPolicy == ErrorPolicyFailFast {
+				break
+			}
+		}
+	}
+	return h.combineErrors(errs)
+}
+
+// SetParallel makes the handler send each entry to all children concurrently so a slow
+// destination does not stall the others. Each child then encodes the entry itself. A child that takes longer than timeout is
+// reported as ErrHandlerTimeout and left to finish in the background (0 = no timeout)
+func (h *MultiHandler) SetParallel(parallel bool, timeout time.Duration) {
+	h.parallel = parallel
+	h.timeout = timeout
+}
+
+// SetErrorPolicy sets how failures of child handlers are reported (default ErrorPolicyLast)
+// Child errors are wrapped in *HandlerError so the failing handler can be identified
+func (h *MultiHandler) SetErrorPolicy(policy ErrorPolicy) {
+	if policy == "" {
+		policy = ErrorPolicyLast
+	}
+	h.errorPolicy = policy
+}
+
+// handleParallel dispatches entry to every child concurrently
+func (h *MultiHandler) handleParallel(entry Entry) error {
+	type result struct {
+		index int
+		err   error
+	}
+
+	// Buffered so children that outlive the timeout never block
+	results := make(chan result, len(h.handlers))
+	for i, handler := range h.handlers {
+		go func() {
+			results <- result{index: i, err: handler.Handle(entry)}
+		}()
+	}
+
+	var deadline <-chan time.Time
+	if h.timeout > 0 {
+		timer := time.NewTimer(h.timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	errs := make([]error, len(h.handlers))
+	done := make([]bool, len(h.handlers))
+	for range h.handlers {
+		select {
+		case r := <-results:
+			done[r.index] = true
+			if r.err != nil {
+				errs[r.index] = h.wrapError(r.index, r.err)
+				if h.errorPolicy == ErrorPolicyFailFast {
+					return errs[r.index]
+				}
+			}
+		case <-deadline:
+			for i := range errs {
+				if !done[i] {
+					errs[i] = h.wrapError(i, ErrHandlerTimeout)
+				}
+			}
+			return h.combineErrors(errs)
+		}
+	}
+	return h.combineErrors(errs)
+}
+
+// wrapError attaches the failing child to err
+func (h *MultiHandler) wrapError(index int, err error) error {
+	return &HandlerError{Index: index, Handler: h.handlers[index], Err: err}
+}
+
+// combineErrors reduces per-child errors (nil entries are successes) according to the error policy
+func (h *MultiHandler) combineErrors(errs []error) error {
+	if h.errorPolicy == ErrorPolicyJoin {
+		return errors.Join(errs...)
+	}
+	// Last and fail-fast: fail-fast stops dispatching, so its only error is also the last
+	for i := len(errs) - 1; i >= 0; i-- {
+		if errs[i] != nil {
+			return errs[i]
+		}
+	}
+	return nil
+}
+
+// Drain implements the Drainer interface for every asynchronous child handler
+func (h *MultiHandler) Drain(ctx context.Context) (int, error) {
+	var dropped int
+	var errs []error
 	for _, handler := range h.handlers {
-		if err := handler.Handle(entry); err != nil {
-			lastErr = err
+		if d, ok := handler.(Drainer); ok {
+			n, err := d.Drain(ctx)
+			dropped += n
+			errs = append(errs, err)
+		}
+	}
+	return dropped, errors.Join(errs...)
+}
+
+// Flush flushes every child handler that supports flushing
+func (h *MultiHandler) Flush() error {
+	var errs []error
+	for _, handler := range h.handlers {
+		if f, ok := handler.(Flusher); ok {
+			if err := f.Flush(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes every child handler that supports closing
+func (h *MultiHandler) Close() error {
+	var errs []error
+	for _, handler := range h.handlers {
+		if c, ok := handler.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				errs = append(errs, err)
+			}
 		}
 	}
-	return lastErr
+	return errors.Join(errs...)
 }
 
 // WithFields implements the Handler interface
@@ -211,5 +699,8 @@ func (h *MultiHandler) WithFields(fields []Field) Handler {
 	for i, handler := range h.handlers {
 		newHandlers[i] = handler.WithFields(fields)
 	}
-	return NewMultiHandler(newHandlers...)
+	child := NewMultiHandler(newHandlers...)
+	child.SetParallel(h.parallel, h.timeout)
+	child.SetErrorPolicy(h.errorPolicy)
+	return child
 }
@@ -0,0 +1,107 @@
+package logpy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// jsonIndent is the indentation unit used for pretty-printed output
+const jsonIndent = "  "
+
+// jsonEncoder builds a JSON object incrementally, preserving member order
+type jsonEncoder struct {
+	buf      bytes.Buffer
+	pretty   bool
+	keyColor string // ANSI color for object keys, empty for none
+	depth    int
+	empty    bool // true until the first member of the current object is written
+}
+
+// openObject starts a new JSON object
+func (e *jsonEncoder) openObject() {
+	e.buf.WriteByte('{')
+	e.depth++
+	e.empty = true
+}
+
+// closeObject ends the current JSON object
+func (e *jsonEncoder) closeObject() {
+	e.depth--
+	if e.pretty && !e.empty {
+		e.newline()
+	}
+	e.buf.WriteByte('}')
+	e.empty = false
+}
+
+// newline starts a new line indented to the current depth
+func (e *jsonEncoder) newline() {
+	e.buf.WriteByte('\n')
+	e.buf.WriteString(strings.Repeat(jsonIndent, e.depth))
+}
+
+// key writes a quoted object key and colon, preceded by a comma
+// unless it is the first member of the enclosing object
+func (e *jsonEncoder) key(key string) error {
+	encoded, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+	if !e.empty {
+		e.buf.WriteByte(',')
+	}
+	if e.pretty {
+		e.newline()
+	}
+	if e.keyColor != "" {
+		e.buf.WriteString(e.keyColor)
+		e.buf.Write(encoded)
+		e.buf.WriteString(colorReset)
+	} else {
+		e.buf.Write(encoded)
+	}
+	e.buf.WriteByte(':')
+	if e.pretty {
+		e.buf.WriteByte(' ')
+	}
+	e.empty = false
+	return nil
+}
+
+// field writes a key/value member to the current object
+func (e *jsonEncoder) field(key string, value interface{}) error {
+	encoded, err := e.marshal(value)
+	if err != nil {
+		return err
+	}
+	return e.rawField(key, encoded)
+}
+
+// rawField writes a member whose value is already encoded
+func (e *jsonEncoder) rawField(key string, encoded []byte) error {
+	if err := e.key(key); err != nil {
+		return err
+	}
+	e.buf.Write(encoded)
+	return nil
+}
+
+// marshal encodes a value, indented to the current depth when pretty
+func (e *jsonEncoder) marshal(value interface{}) ([]byte, error) {
+	return marshalJSON(value, strings.Repeat(jsonIndent, e.depth), e.pretty)
+}
+
+// marshalJSON encodes value, turning a panic in a MarshalJSON method into an error
+func marshalJSON(value interface{}, prefix string, pretty bool) (data []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic encoding value: %v", r)
+		}
+	}()
+	if pretty {
+		return json.MarshalIndent(value, prefix, jsonIndent)
+	}
+	return json.Marshal(value)
+}
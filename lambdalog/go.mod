@@ -0,0 +1,10 @@
+module github.com/nhatpy/logpy/lambdalog
+
+go 1.25.0
+
+require (
+	github.com/aws/aws-lambda-go v1.54.0
+	github.com/nhatpy/logpy v0.0.0-20261016204738-105601b6e2d3
+)
+
+require gopkg.in/natefinch/lumberjack.v2 v2.2.1 // indirect
@@ -0,0 +1,117 @@
+// Package lambdalog configures logpy for AWS Lambda: JSON lines on stdout for
+// CloudWatch, the invocation's requestId on every entry logged through Ctx, a
+// coldStart field, and a flush before each invocation returns, since the runtime
+// freezes the environment as soon as the handler is done.
+//
+// Example:
+//
+//	var logger = lambdalog.NewLogger()
+//
+//	func handle(ctx context.Context, event events.SQSEvent) (string, error) {
+//		logger.Ctx(ctx).Info().Int("records", len(event.Records)).Msg("processing")
+//		return "ok", nil
+//	}
+//
+//	func main() {
+//		lambda.Start(lambdalog.Wrap(logger, handle))
+//	}
+package lambdalog
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/nhatpy/logpy"
+)
+
+const (
+	// RequestIDKey is the key of the invocation's request ID field
+	RequestIDKey = "requestId"
+	// ColdStartKey is the key of the field that is true in the first invocation of an environment
+	ColdStartKey = "coldStart"
+)
+
+// coldStartKey is the context key for the cold start flag set by Wrap
+type coldStartKey struct{}
+
+// warm is set once the first invocation has started
+var warm atomic.Bool
+
+// Config returns the Lambda preset: JSON to stdout without files, caller info, the
+// level from AWS_LAMBDA_LOG_LEVEL or LOG_LEVEL (default Info), the function name and
+// version as fields, and Extractor for Logger.Ctx
+// Lambda's TRACE maps to Debug and FATAL to Error; unknown names fall back to Info
+func Config() logpy.Config {
+	level := logpy.InfoLevel
+	for _, env := range []string{"AWS_LAMBDA_LOG_LEVEL", "LOG_LEVEL"} {
+		if s := os.Getenv(env); s != "" {
+			level = lambdaLevel(s)
+			break
+		}
+	}
+
+	var fields []logpy.Field
+	if lambdacontext.FunctionName != "" {
+		fields = append(fields,
+			logpy.String("function", lambdacontext.FunctionName),
+			logpy.String("version", lambdacontext.FunctionVersion),
+		)
+	}
+	return logpy.Config{
+		Level:             level,
+		Format:            logpy.FormatJSON,
+		Output:            logpy.OutputStdout,
+		AddCaller:         true,
+		DefaultFields:     fields,
+		ContextExtractors: []logpy.ContextExtractor{Extractor()},
+	}
+}
+
+// NewLogger creates a logger with the Lambda preset
+func NewLogger() *logpy.Logger {
+	return logpy.NewWithConfig(Config())
+}
+
+// Extractor returns a context extractor adding the request ID of the invocation in
+// ctx and, in contexts passed through Wrap, whether it is a cold start
+func Extractor() logpy.ContextExtractor {
+	return func(ctx context.Context) []logpy.Field {
+		var fields []logpy.Field
+		if lc, ok := lambdacontext.FromContext(ctx); ok && lc.AwsRequestID != "" {
+			fields = append(fields, logpy.String(RequestIDKey, lc.AwsRequestID))
+		}
+		if cold, ok := ctx.Value(coldStartKey{}).(bool); ok {
+			fields = append(fields, logpy.Bool(ColdStartKey, cold))
+		}
+		return fields
+	}
+}
+
+// Wrap returns a Lambda handler that marks the first invocation as a cold start and
+// flushes logger before returning, also when fn panics, so no entries are lost
+// when the runtime freezes the environment
+func Wrap[TIn, TOut any](logger *logpy.Logger, fn func(context.Context, TIn) (TOut, error)) func(context.Context, TIn) (TOut, error) {
+	return func(ctx context.Context, in TIn) (TOut, error) {
+		ctx = context.WithValue(ctx, coldStartKey{}, !warm.Swap(true))
+		defer logger.Flush()
+		return fn(ctx, in)
+	}
+}
+
+// lambdaLevel converts a Lambda log level (TRACE, DEBUG, INFO, WARN, ERROR, FATAL)
+// to the closest logpy level
+func lambdaLevel(s string) logpy.Level {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "TRACE":
+		return logpy.DebugLevel
+	case "FATAL":
+		return logpy.ErrorLevel
+	}
+	if level, err := logpy.ParseLevelStrict(s); err == nil {
+		return level
+	}
+	return logpy.InfoLevel
+}
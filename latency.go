@@ -0,0 +1,314 @@
+package logpy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"sync"
+	"time"
+)
+
+// latencyBuckets covers every int64 duration: 8 sub-buckets per power of two,
+// so percentiles are accurate to within 12.5%
+const latencyBuckets = 62*8 + 8
+
+// LatencyStats summarises the durations recorded for one field over an interval
+type LatencyStats struct {
+	Key   string
+	Count int64
+	Min   time.Duration
+	Max   time.Duration
+	P50   time.Duration
+	P95   time.Duration
+	P99   time.Duration
+}
+
+// LatencyHandler passes entries to an inner handler and aggregates the values of
+// configured Dur fields (e.g. "latency") into histograms. Every interval it logs one
+// summary entry per field with the count, min, max and p50/p95/p99 to inner and starts
+// a new histogram, turning existing request logs into lightweight performance telemetry
+type LatencyHandler struct {
+	inner    Handler
+	keys     []string
+	interval time.Duration
+	level    Level
+	log      bool
+	onError  ErrorHandler // Reports failures of summaries emitted in the background
+	clock    Clock        // Timestamps summaries (nil = time.Now)
+
+	mu      sync.Mutex
+	current map[string]*latencyHistogram
+	last    []LatencyStats
+	stop    chan struct{}
+	stopped sync.Once
+}
+
+// latencyHistogram counts durations in logarithmic buckets
+type latencyHistogram struct {
+	counts   [latencyBuckets]int64
+	count    int64
+	min, max time.Duration
+}
+
+// NewLatencyHandler creates a handler that aggregates the Dur fields named by keys
+// (nested group fields use dotted keys) and summarises them every interval
+// (default 1 minute)
+func NewLatencyHandler(inner Handler, interval time.Duration, keys ...string) *LatencyHandler {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	h := &LatencyHandler{
+		inner:    inner,
+		keys:     keys,
+		interval: interval,
+		level:    InfoLevel,
+		log:      true,
+		current:  make(map[string]*latencyHistogram, len(keys)),
+		stop:     make(chan struct{}),
+	}
+	go h.run()
+	return h
+}
+
+// run emits summaries at the end of every interval
+func (h *LatencyHandler) run() {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if summary, err := h.emit(); err != nil {
+				reportError(h.onError, err, summary)
+			}
+		case <-h.stop:
+			return
+		}
+	}
+}
+
+// SetSummaryLevel sets the level of the summary entries (default Info)
+// It should be called before logging
+func (h *LatencyHandler) SetSummaryLevel(level Level) {
+	h.level = level
+}
+
+// SetLogSummary turns the periodic summary entries on or off (default on); Stats
+// is updated either way
+// It should be called before logging
+func (h *LatencyHandler) SetLogSummary(enabled bool) {
+	h.log = enabled
+}
+
+// SetClock sets the clock that timestamps summaries (nil means time.Now)
+// Use the logger's Config.Clock so summaries line up with other entries in tests
+func (h *LatencyHandler) SetClock(clock Clock) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.clock = clock
+}
+
+// SetErrorHandler reports failures of summaries emitted every interval to fn
+// They happen in the background, so the logger's error handler never sees them
+// nil prints them to stderr. Flush, Drain and Close return their failures instead
+func (h *LatencyHandler) SetErrorHandler(fn ErrorHandler) {
+	h.onError = fn
+}
+
+// Stats returns the summaries of the last completed interval, in key order
+// Fields without values in the interval are omitted
+func (h *LatencyHandler) Stats() []LatencyStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]LatencyStats(nil), h.last...)
+}
+
+// Enabled implements the Handler interface
+func (h *LatencyHandler) Enabled(level Level) bool {
+	return h.inner.Enabled(level)
+}
+
+// Handle implements the Handler interface
+func (h *LatencyHandler) Handle(entry Entry) error {
+	entry = entry.resolveLazy()
+	h.record(entry)
+	return h.inner.Handle(entry)
+}
+
+// record adds the configured duration fields of entry to the histograms
+func (h *LatencyHandler) record(entry Entry) {
+	for _, key := range h.keys {
+		field, ok := findField(entry, key)
+		if !ok || field.Type != DurationType {
+			continue
+		}
+		d, ok := field.Value.(time.Duration)
+		if !ok {
+			continue
+		}
+
+		h.mu.Lock()
+		hist, ok := h.current[key]
+		if !ok {
+			hist = &latencyHistogram{}
+			h.current[key] = hist
+		}
+		hist.add(d)
+		h.mu.Unlock()
+	}
+}
+
+// emit publishes the current interval's summaries and starts a new interval
+// It returns the failures along with the first summary that failed, for reporting
+func (h *LatencyHandler) emit() (Entry, error) {
+	h.mu.Lock()
+	current := h.current
+	h.current = make(map[string]*latencyHistogram, len(h.keys))
+	var stats []LatencyStats
+	for _, key := range h.keys {
+		if hist, ok := current[key]; ok {
+			stats = append(stats, hist.stats(key))
+		}
+	}
+	h.last = stats
+	now := clockNow(h.clock)
+	h.mu.Unlock()
+
+	if !h.log {
+		return Entry{}, nil
+	}
+	var failed Entry
+	var errs []error
+	for _, s := range stats {
+		summary := Entry{
+			Time:    now,
+			Level:   h.level,
+			Message: fmt.Sprintf("%s stats for last %s", s.Key, h.interval),
+			Fields: []Field{
+				String("key", s.Key),
+				Int64("count", s.Count),
+				Duration("min", s.Min),
+				Duration("p50", s.P50),
+				Duration("p95", s.P95),
+				Duration("p99", s.P99),
+				Duration("max", s.Max),
+			},
+		}
+		if err := h.inner.Handle(summary); err != nil {
+			if len(errs) == 0 {
+				failed = summary
+			}
+			errs = append(errs, err)
+		}
+	}
+	return failed, errors.Join(errs...)
+}
+
+// add records one duration; negative durations count as zero
+func (hist *latencyHistogram) add(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	if hist.count == 0 || d < hist.min {
+		hist.min = d
+	}
+	if d > hist.max {
+		hist.max = d
+	}
+	hist.count++
+	hist.counts[latencyBucket(d)]++
+}
+
+// stats summarises the histogram
+func (hist *latencyHistogram) stats(key string) LatencyStats {
+	return LatencyStats{
+		Key:   key,
+		Count: hist.count,
+		Min:   hist.min,
+		Max:   hist.max,
+		P50:   hist.quantile(0.50),
+		P95:   hist.quantile(0.95),
+		P99:   hist.quantile(0.99),
+	}
+}
+
+// quantile returns the upper bound of the bucket holding quantile q, capped at the maximum
+func (hist *latencyHistogram) quantile(q float64) time.Duration {
+	rank := int64(q*float64(hist.count) + 0.5)
+	if rank < 1 {
+		rank = 1
+	}
+	var seen int64
+	for i, n := range hist.counts {
+		seen += n
+		if seen >= rank {
+			d := latencyBucketUpper(i)
+			if d > hist.max {
+				d = hist.max
+			}
+			if d < hist.min {
+				d = hist.min
+			}
+			return d
+		}
+	}
+	return hist.max
+}
+
+// latencyBucket returns the bucket of d: exact below 8ns, then 8 per power of two
+func latencyBucket(d time.Duration) int {
+	ns := uint64(d)
+	if ns < 8 {
+		return int(ns)
+	}
+	n := bits.Len64(ns) // 4..63
+	mantissa := (ns >> (n - 4)) & 7
+	return (n-3)*8 + int(mantissa)
+}
+
+// latencyBucketUpper returns the largest duration in bucket i
+func latencyBucketUpper(i int) time.Duration {
+	if i < 8 {
+		return time.Duration(i)
+	}
+	shift := i/8 - 1 // n-4
+	lower := uint64(8+i%8) << shift
+	return time.Duration(lower + (1 << shift) - 1)
+}
+
+// WithFields implements the Handler interface
+func (h *LatencyHandler) WithFields(fields []Field) Handler {
+	return h
+}
+
+// Flush emits summaries for the current interval and flushes the inner handler
+func (h *LatencyHandler) Flush() error {
+	_, err := h.emit()
+	if f, ok := h.inner.(Flusher); ok {
+		err = errors.Join(err, f.Flush())
+	}
+	return err
+}
+
+// Drain implements the Drainer interface for an asynchronous inner handler
+func (h *LatencyHandler) Drain(ctx context.Context) (int, error) {
+	h.stopped.Do(func() { close(h.stop) })
+	_, err := h.emit()
+	if d, ok := h.inner.(Drainer); ok {
+		n, drainErr := d.Drain(ctx)
+		return n, errors.Join(err, drainErr)
+	}
+	return 0, err
+}
+
+// Close emits the final summaries, stops the background goroutine and closes the inner handler
+func (h *LatencyHandler) Close() error {
+	h.stopped.Do(func() { close(h.stop) })
+	err := h.Flush()
+	if c, ok := h.inner.(io.Closer); ok {
+		err = errors.Join(err, c.Close())
+	}
+	return err
+}
@@ -1,8 +1,17 @@
 package logpy
 
-import "strings"
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
 
 // Level represents log severity levels
+// Custom levels made with CustomLevel are ordered between and around the built-in
+// levels; compare levels with AtLeast so custom levels sort correctly
 type Level int8
 
 const (
@@ -16,6 +25,156 @@ const (
 	ErrorLevel
 )
 
+// builtinLevels are the predefined levels in ascending order
+var builtinLevels = []Level{DebugLevel, InfoLevel, WarnLevel, ErrorLevel}
+
+// levelStep is the distance in level order between adjacent built-in levels; custom
+// levels take the positions in between
+const levelStep = 16
+
+// customLevelBase is the value of the custom level that would be ordered like
+// DebugLevel, so custom levels use values above customLevelBase-levelStep and never
+// collide with the built-in levels
+const customLevelBase = 64
+
+// CustomLevel returns a level for RegisterLevel ordered offset positions above base,
+// or below it when offset is negative:
+//
+//	NoticeLevel := logpy.CustomLevel(logpy.InfoLevel, 8)    // Between Info and Warn
+//	TraceLevel := logpy.CustomLevel(logpy.DebugLevel, -8)   // Below Debug
+//
+// Adjacent built-in levels are 16 positions apart, so offset must be between
+// -15 and 15 and not 0. CustomLevel panics if base is not a built-in level
+func CustomLevel(base Level, offset int) Level {
+	if !isBuiltinLevel(base) || offset == 0 || offset <= -levelStep || offset >= levelStep {
+		panic(fmt.Sprintf("logpy: invalid custom level %s%+d", base, offset))
+	}
+	return Level(customLevelBase + int(base)*levelStep + offset)
+}
+
+// isCustomRange reports whether l is in the value range of CustomLevel
+func isCustomRange(l Level) bool {
+	return l > customLevelBase-levelStep
+}
+
+// order returns the position of l in level order, where built-in levels are levelStep apart
+func (l Level) order() int {
+	if isCustomRange(l) {
+		return int(l) - customLevelBase
+	}
+	return int(l) * levelStep
+}
+
+// AtLeast reports whether l is ordered at or above min. Unlike comparing the values,
+// it places custom levels between the built-in levels they were made from
+func (l Level) AtLeast(min Level) bool {
+	return l.order() >= min.order()
+}
+
+// levelNumber returns the number of l: 0-3 for the built-in levels and fractions for
+// custom levels in between (CustomLevel(InfoLevel, 8) is 1.5)
+func levelNumber(l Level) interface{} {
+	if l.order()%levelStep == 0 {
+		return l.order() / levelStep
+	}
+	return float64(l.order()) / levelStep
+}
+
+// levelFromNumber converts a number written by levelNumber back to a known level
+func levelFromNumber(n float64) (Level, bool) {
+	order := n * levelStep
+	if order != float64(int(order)) {
+		return InfoLevel, false
+	}
+	for _, level := range Levels() {
+		if level.order() == int(order) {
+			return level, true
+		}
+	}
+	return InfoLevel, false
+}
+
+// LevelDef describes a custom level registered with RegisterLevel
+type LevelDef struct {
+	Name     string // Name written by formatters, e.g. "NOTICE"; ParseLevel accepts any case
+	Color    string // ANSI color of the level in console output (uncolored when empty)
+	Severity int    // RFC 5424 severity (0 uses the severity of the next lower built-in level)
+}
+
+var customLevels = struct {
+	mu     sync.RWMutex
+	defs   map[Level]LevelDef
+	byName map[string]Level
+}{
+	defs:   make(map[Level]LevelDef),
+	byName: make(map[string]Level),
+}
+
+// RegisterLevel adds a custom level made with CustomLevel:
+//
+//	var NoticeLevel = logpy.CustomLevel(logpy.InfoLevel, 8)   // Between Info and Warn
+//	var CriticalLevel = logpy.CustomLevel(logpy.ErrorLevel, 8) // Above Error
+//
+//	logpy.RegisterLevel(NoticeLevel, logpy.LevelDef{Name: "NOTICE", Color: "\033[32m", Severity: 5})
+//
+// Log at the level with Logger.Log. It is meant to be called from init; it panics
+// if the name is empty, the level was not made with CustomLevel, or the level or name
+// is already in use
+func RegisterLevel(level Level, def LevelDef) {
+	name := strings.ToUpper(def.Name)
+	if name == "" || !isCustomRange(level) {
+		panic(fmt.Sprintf("logpy: cannot register level %d %q", level, def.Name))
+	}
+	if _, ok := parseBuiltinLevel(name); ok {
+		panic(fmt.Sprintf("logpy: level name %q is built in", def.Name))
+	}
+	customLevels.mu.Lock()
+	defer customLevels.mu.Unlock()
+	if _, dup := customLevels.defs[level]; dup {
+		panic(fmt.Sprintf("logpy: level %d registered twice", level))
+	}
+	if _, dup := customLevels.byName[name]; dup {
+		panic(fmt.Sprintf("logpy: level name %q registered twice", def.Name))
+	}
+	def.Name = name
+	customLevels.defs[level] = def
+	customLevels.byName[name] = level
+}
+
+// customLevel returns the definition of a registered level
+func customLevel(level Level) (LevelDef, bool) {
+	customLevels.mu.RLock()
+	defer customLevels.mu.RUnlock()
+	def, ok := customLevels.defs[level]
+	return def, ok
+}
+
+// Levels returns the built-in and registered levels in ascending order
+func Levels() []Level {
+	levels := append([]Level(nil), builtinLevels...)
+	customLevels.mu.RLock()
+	for level := range customLevels.defs {
+		levels = append(levels, level)
+	}
+	customLevels.mu.RUnlock()
+	sort.Slice(levels, func(i, j int) bool { return levels[i].order() < levels[j].order() })
+	return levels
+}
+
+// isBuiltinLevel reports whether level is one of the predefined levels
+func isBuiltinLevel(level Level) bool {
+	return level == DebugLevel || level == InfoLevel || level == WarnLevel || level == ErrorLevel
+}
+
+// isKnownLevel reports whether level is built in or registered
+func isKnownLevel(level Level) bool {
+	if isBuiltinLevel(level) {
+		return true
+	}
+	_, ok := customLevel(level)
+	return ok
+}
+
 // String returns the string representation of the log level
 func (l Level) String() string {
 	switch l {
@@ -28,22 +187,146 @@ func (l Level) String() string {
 	case ErrorLevel:
 		return "ERROR"
 	default:
+		if def, ok := customLevel(l); ok {
+			return def.Name
+		}
 		return "UNKNOWN"
 	}
 }
 
-// ParseLevel converts a string to a Level
+// Severity returns the RFC 5424 (syslog) numeric severity of l:
+// 7 debug, 6 informational, 4 warning, 3 error
+func (l Level) Severity() int {
+	switch l {
+	case DebugLevel:
+		return 7
+	case InfoLevel:
+		return 6
+	case WarnLevel:
+		return 4
+	case ErrorLevel:
+		return 3
+	}
+	if def, ok := customLevel(l); ok && def.Severity != 0 {
+		return def.Severity
+	}
+	switch {
+	case l.AtLeast(ErrorLevel):
+		return ErrorLevel.Severity()
+	case l.AtLeast(WarnLevel):
+		return WarnLevel.Severity()
+	case l.AtLeast(InfoLevel):
+		return InfoLevel.Severity()
+	default:
+		return DebugLevel.Severity()
+	}
+}
+
+// color returns the console color of l from c, or the color of a registered level
+func (l Level) color(c ColorConfig) string {
+	switch l {
+	case DebugLevel:
+		return c.Debug
+	case InfoLevel:
+		return c.Info
+	case WarnLevel:
+		return c.Warn
+	case ErrorLevel:
+		return c.Error
+	}
+	def, _ := customLevel(l)
+	return def.Color
+}
+
+// Ptr returns a pointer to a copy of l, for optional level settings such as
+// Config.ConsoleLevel: cfg.ConsoleLevel = logpy.DebugLevel.Ptr()
+func (l Level) Ptr() *Level {
+	return &l
+}
+
+// ParseLevel converts a string to a Level, including registered level names
+// Unknown names default to Info; use ParseLevelStrict to reject them
 func ParseLevel(s string) (Level, error) {
+	if level, err := ParseLevelStrict(s); err == nil {
+		return level, nil
+	}
+	return InfoLevel, nil // Default to Info if unknown
+}
+
+// ParseLevelStrict converts a level name (any case, "warning" for Warn, or a registered
+// name) to a Level and returns an error for unknown names, e.g. a typo in a config file
+// Level numbers as written by LevelNumber are accepted too: "0" to "3" for Debug to Error
+func ParseLevelStrict(s string) (Level, error) {
+	if level, ok := parseBuiltinLevel(s); ok {
+		return level, nil
+	}
+	if n, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+		if level, ok := levelFromNumber(n); ok {
+			return level, nil
+		}
+		return InfoLevel, fmt.Errorf("logpy: unknown level %q", s)
+	}
+	customLevels.mu.RLock()
+	level, ok := customLevels.byName[strings.ToUpper(s)]
+	customLevels.mu.RUnlock()
+	if !ok {
+		return InfoLevel, fmt.Errorf("logpy: unknown level %q", s)
+	}
+	return level, nil
+}
+
+// MarshalText implements encoding.TextMarshaler, so levels are written by name
+// in JSON, YAML and TOML config files
+func (l Level) MarshalText() ([]byte, error) {
+	if !isKnownLevel(l) {
+		return nil, fmt.Errorf("logpy: unknown level %d", l)
+	}
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler with ParseLevelStrict, so
+// config files with an unknown level name fail to load
+func (l *Level) UnmarshalText(text []byte) error {
+	level, err := ParseLevelStrict(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// UnmarshalJSON accepts level names and level numbers (0-3 for Debug to Error)
+func (l *Level) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		return l.UnmarshalText([]byte(s))
+	}
+	var n float64
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("logpy: invalid level %s", data)
+	}
+	level, ok := levelFromNumber(n)
+	if !ok {
+		return fmt.Errorf("logpy: unknown level %s", data)
+	}
+	*l = level
+	return nil
+}
+
+// parseBuiltinLevel converts the name of a built-in level
+func parseBuiltinLevel(s string) (Level, bool) {
 	switch strings.ToUpper(s) {
 	case "DEBUG":
-		return DebugLevel, nil
+		return DebugLevel, true
 	case "INFO":
-		return InfoLevel, nil
+		return InfoLevel, true
 	case "WARN", "WARNING":
-		return WarnLevel, nil
+		return WarnLevel, true
 	case "ERROR":
-		return ErrorLevel, nil
-	default:
-		return InfoLevel, nil // Default to Info if unknown
+		return ErrorLevel, true
 	}
+	return InfoLevel, false
 }
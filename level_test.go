@@ -0,0 +1,180 @@
+package logpy
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+)
+
+var (
+	testTraceLevel    = CustomLevel(DebugLevel, -8)
+	testNoticeLevel   = CustomLevel(InfoLevel, 8)
+	testCriticalLevel = CustomLevel(ErrorLevel, 8)
+
+	registerTestLevelsOnce sync.Once
+)
+
+// registerTestLevels registers the test levels once for the whole package
+func registerTestLevels() {
+	registerTestLevelsOnce.Do(func() {
+		RegisterLevel(testTraceLevel, LevelDef{Name: "TESTTRACE"})
+		RegisterLevel(testNoticeLevel, LevelDef{Name: "TESTNOTICE", Severity: 5})
+		RegisterLevel(testCriticalLevel, LevelDef{Name: "TESTCRITICAL"})
+	})
+}
+
+func TestBuiltinLevelValues(t *testing.T) {
+	for want, level := range []Level{DebugLevel, InfoLevel, WarnLevel, ErrorLevel} {
+		if int(level) != want {
+			t.Errorf("%s = %d, want %d", level, level, want)
+		}
+	}
+}
+
+func TestLevelAtLeast(t *testing.T) {
+	// Ascending level order
+	order := []Level{testTraceLevel, DebugLevel, InfoLevel, testNoticeLevel, WarnLevel, ErrorLevel, testCriticalLevel}
+	for i, l := range order {
+		for j, min := range order {
+			if got, want := l.AtLeast(min), i >= j; got != want {
+				t.Errorf("Level(%d).AtLeast(Level(%d)) = %v, want %v", l, min, got, want)
+			}
+		}
+	}
+}
+
+func TestLevelsSorted(t *testing.T) {
+	registerTestLevels()
+	levels := Levels()
+	for i := 1; i < len(levels); i++ {
+		if !levels[i].AtLeast(levels[i-1]) {
+			t.Fatalf("Levels() = %v, not in ascending order", levels)
+		}
+	}
+}
+
+func TestCustomLevelRejectsInvalidOffsets(t *testing.T) {
+	tests := []struct {
+		name   string
+		base   Level
+		offset int
+	}{
+		{"zero offset", InfoLevel, 0},
+		{"offset reaching next level", InfoLevel, 16},
+		{"offset reaching previous level", InfoLevel, -16},
+		{"custom base", testNoticeLevel, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("CustomLevel(%d, %d) did not panic", tt.base, tt.offset)
+				}
+			}()
+			CustomLevel(tt.base, tt.offset)
+		})
+	}
+}
+
+func TestRegisterLevelRejectsBuiltinRange(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("RegisterLevel(Level(5)) did not panic")
+		}
+	}()
+	RegisterLevel(Level(5), LevelDef{Name: "OUTOFRANGE"})
+}
+
+func TestLevelNumber(t *testing.T) {
+	tests := []struct {
+		level Level
+		want  interface{}
+	}{
+		{DebugLevel, 0},
+		{InfoLevel, 1},
+		{WarnLevel, 2},
+		{ErrorLevel, 3},
+		{testNoticeLevel, 1.5},
+		{testTraceLevel, -0.5},
+		{testCriticalLevel, 3.5},
+	}
+	for _, tt := range tests {
+		if got := levelNumber(tt.level); got != tt.want {
+			t.Errorf("levelNumber(%d) = %v, want %v", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestLevelSeverity(t *testing.T) {
+	registerTestLevels()
+	tests := []struct {
+		level Level
+		want  int
+	}{
+		{DebugLevel, 7},
+		{InfoLevel, 6},
+		{WarnLevel, 4},
+		{ErrorLevel, 3},
+		{testNoticeLevel, 5},   // Set in LevelDef
+		{testCriticalLevel, 3}, // Severity of Error
+		{testTraceLevel, 7},    // Severity of Debug
+	}
+	for _, tt := range tests {
+		if got := tt.level.Severity(); got != tt.want {
+			t.Errorf("%s.Severity() = %d, want %d", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestParseLevelStrict(t *testing.T) {
+	registerTestLevels()
+	tests := []struct {
+		in      string
+		want    Level
+		wantErr bool
+	}{
+		{"debug", DebugLevel, false},
+		{"INFO", InfoLevel, false},
+		{"warning", WarnLevel, false},
+		{"Error", ErrorLevel, false},
+		{"testnotice", testNoticeLevel, false},
+		{"0", DebugLevel, false},
+		{"2", WarnLevel, false},
+		{" 3 ", ErrorLevel, false},
+		{"1.5", testNoticeLevel, false},
+		{"4", InfoLevel, true},
+		{"1.25", InfoLevel, true},
+		{"wraning", InfoLevel, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevelStrict(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseLevelStrict(%q) = %d, %v, want %d, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestLevelUnmarshalJSON(t *testing.T) {
+	registerTestLevels()
+	tests := []struct {
+		in      string
+		want    Level
+		wantErr bool
+	}{
+		{`"warn"`, WarnLevel, false},
+		{`"TESTCRITICAL"`, testCriticalLevel, false},
+		{`1`, InfoLevel, false},
+		{`3`, ErrorLevel, false},
+		{`3.5`, testCriticalLevel, false},
+		{`7`, DebugLevel, true},
+		{`"nope"`, DebugLevel, true},
+		{`true`, DebugLevel, true},
+	}
+	for _, tt := range tests {
+		var got Level
+		err := json.Unmarshal([]byte(tt.in), &got)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("Unmarshal(%s) = %d, %v, want %d, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
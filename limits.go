@@ -0,0 +1,283 @@
+package logpy
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"unicode/utf8"
+)
+
+// truncatedMarker is appended to values cut by a size limit
+const truncatedMarker = "...(truncated)"
+
+// maxDepthMarker replaces groups and Any values nested deeper than the depth limit
+const maxDepthMarker = "!MAXDEPTH"
+
+// fixedFieldSize is counted for values that cannot be encoded to measure them
+const fixedFieldSize = 8
+
+// Limits caps the size of entries, so an accidental dump of a large body or a
+// pathological value cannot blow up log files or downstream collectors. Zero means no
+// limit. Sizes are in bytes and do not include the marker added to cut values.
+// Lazy fields are not limited
+type Limits struct {
+	MaxMessageBytes int // Longer messages are cut and marked "...(truncated)"
+	MaxFieldBytes   int // Longer string values, including Any strings and byte slices, are cut
+	MaxEntryBytes   int // Approximate total of message, keys and encoded values; the message is cut first, then fields past it are cut or dropped
+	MaxFields       int // Context and event fields per entry; later fields are dropped
+	MaxDepth        int // Nesting of groups and Any maps, slices and structs; deeper values become "!MAXDEPTH"
+}
+
+// enabled reports whether any limit is set
+func (lim Limits) enabled() bool {
+	return lim.MaxMessageBytes > 0 || lim.MaxFieldBytes > 0 || lim.MaxEntryBytes > 0 ||
+		lim.MaxFields > 0 || lim.MaxDepth > 0
+}
+
+// apply enforces the limits on entry, copying field slices before changing them
+// When fields are dropped, a "truncated_fields" field records how many
+func (lim Limits) apply(entry *Entry) {
+	if lim.MaxMessageBytes > 0 {
+		entry.Message = truncateString(entry.Message, lim.MaxMessageBytes)
+	}
+	if lim.MaxDepth > 0 {
+		limit := func(field Field) (Field, bool) { return limitDepth(field, lim.MaxDepth) }
+		entry.ContextFields, _ = mapFields(entry.ContextFields, limit)
+		entry.Fields, _ = mapFields(entry.Fields, limit)
+	}
+	if lim.MaxFieldBytes > 0 {
+		truncate := func(field Field) (Field, bool) { return truncateField(field, lim.MaxFieldBytes) }
+		entry.ContextFields, _ = mapFields(entry.ContextFields, truncate)
+		entry.Fields, _ = mapFields(entry.Fields, truncate)
+	}
+
+	var dropped int
+	if lim.MaxFields > 0 && len(entry.ContextFields)+len(entry.Fields) > lim.MaxFields {
+		dropped = len(entry.ContextFields) + len(entry.Fields) - lim.MaxFields
+		if len(entry.ContextFields) > lim.MaxFields {
+			entry.ContextFields = entry.ContextFields[:lim.MaxFields:lim.MaxFields]
+		}
+		n := lim.MaxFields - len(entry.ContextFields)
+		entry.Fields = entry.Fields[:n:n]
+	}
+	if lim.MaxEntryBytes > 0 {
+		entry.Message = truncateString(entry.Message, lim.MaxEntryBytes)
+		budget := lim.MaxEntryBytes - min(len(entry.Message), lim.MaxEntryBytes)
+		var n int
+		entry.ContextFields, budget, n = limitFields(entry.ContextFields, budget)
+		dropped += n
+		entry.Fields, _, n = limitFields(entry.Fields, budget)
+		dropped += n
+	}
+	if dropped > 0 {
+		entry.Fields = append(entry.Fields[:len(entry.Fields):len(entry.Fields)], Int("truncated_fields", dropped))
+	}
+}
+
+// truncateString cuts s to at most max bytes on a rune boundary and marks it
+func truncateString(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + truncatedMarker
+}
+
+// mapFields replaces each field changed by fn and reports whether any was
+// fields is returned unchanged when none are, so shared slices are never modified
+func mapFields(fields []Field, fn func(Field) (Field, bool)) ([]Field, bool) {
+	var out []Field
+	for i, field := range fields {
+		changed, ok := fn(field)
+		if !ok {
+			continue
+		}
+		if out == nil {
+			out = append([]Field(nil), fields...)
+		}
+		out[i] = changed
+	}
+	if out == nil {
+		return fields, false
+	}
+	return out, true
+}
+
+// truncateField cuts the string values of field longer than max and reports whether it did
+func truncateField(field Field, max int) (Field, bool) {
+	switch field.Type {
+	case StringType, ErrorType:
+		if s, ok := field.Value.(string); ok && len(s) > max {
+			field.Value = truncateString(s, max)
+			return field, true
+		}
+	case AnyType:
+		switch v := field.Value.(type) {
+		case string:
+			if len(v) > max {
+				return String(field.Key, truncateString(v, max)), true
+			}
+		case []byte:
+			if len(v) > max {
+				return String(field.Key, truncateString(string(v[:min(len(v), max+utf8.UTFMax)]), max)), true
+			}
+		}
+	case StringsType, ErrorsType:
+		vals, ok := field.Value.([]string)
+		if !ok {
+			break
+		}
+		var out []string
+		for i, s := range vals {
+			if len(s) > max {
+				if out == nil {
+					out = append([]string(nil), vals...)
+				}
+				out[i] = truncateString(s, max)
+			}
+		}
+		if out != nil {
+			field.Value = out
+			return field, true
+		}
+	case GroupType:
+		truncate := func(member Field) (Field, bool) { return truncateField(member, max) }
+		if members, ok := mapFields(groupFields(field), truncate); ok {
+			return Group(field.Key, members...), true
+		}
+	}
+	return field, false
+}
+
+// limitDepth replaces the parts of field nested deeper than max with a marker and
+// reports whether it did
+func limitDepth(field Field, max int) (Field, bool) {
+	switch field.Type {
+	case GroupType:
+		if max == 0 {
+			return String(field.Key, maxDepthMarker), true
+		}
+		limit := func(member Field) (Field, bool) { return limitDepth(member, max-1) }
+		if members, ok := mapFields(groupFields(field), limit); ok {
+			return Group(field.Key, members...), true
+		}
+	case AnyType:
+		if exceedsDepth(reflect.ValueOf(field.Value), max) {
+			return String(field.Key, maxDepthMarker), true
+		}
+	}
+	return field, false
+}
+
+// exceedsDepth reports whether maps, slices, arrays and structs nest deeper than max in v
+// The walk stops at max, so it also ends on values that reference themselves
+// Values that encode themselves (MarshalJSON, String, Error) count as scalars
+func exceedsDepth(v reflect.Value, max int) bool {
+	for {
+		if !v.IsValid() || encodesItself(v) {
+			return false
+		}
+		if v.Kind() != reflect.Pointer && v.Kind() != reflect.Interface {
+			break
+		}
+		if v.IsNil() {
+			return false
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+	default:
+		return false
+	}
+	if max == 0 {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if exceedsDepth(iter.Value(), max-1) {
+				return true
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if exceedsDepth(v.Index(i), max-1) {
+				return true
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if exceedsDepth(v.Field(i), max-1) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// encodesItself reports whether v has a method that formatters use instead of its contents
+func encodesItself(v reflect.Value) bool {
+	if !v.CanInterface() {
+		return false
+	}
+	switch v.Interface().(type) {
+	case json.Marshaler, fmt.Stringer, error:
+		return true
+	}
+	return false
+}
+
+// limitFields keeps fields within budget bytes, cutting the string value that crosses it
+// and dropping the rest. It returns the kept fields, the remaining budget and the number dropped
+func limitFields(fields []Field, budget int) ([]Field, int, int) {
+	for i, field := range fields {
+		size := fieldSize(field)
+		if size <= budget {
+			budget -= size
+			continue
+		}
+
+		kept := fields[:i:i]
+		room := budget - len(field.Key)
+		if s, ok := field.Value.(string); ok && (field.Type == StringType || field.Type == ErrorType) && room > 0 {
+			field.Value = truncateString(s, room)
+			kept = append(kept, field)
+			i++
+		}
+		return kept, 0, len(fields) - i
+	}
+	return fields, budget, 0
+}
+
+// fieldSize approximates the encoded size of a field's key and value
+// Strings count their length; other values the length of their JSON encoding
+func fieldSize(field Field) int {
+	size := len(field.Key)
+	switch v := field.Value.(type) {
+	case string:
+		size += len(v)
+	case []byte:
+		size += len(v)
+	case []string:
+		for _, s := range v {
+			size += len(s)
+		}
+	case []Field:
+		for _, member := range v {
+			size += fieldSize(member)
+		}
+	default:
+		if data, err := marshalJSON(v, "", false); err == nil {
+			size += len(data)
+		} else {
+			size += fixedFieldSize
+		}
+	}
+	return size
+}
@@ -1,9 +1,91 @@
 package logpy
 
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
 // Logger is the main logging interface
 type Logger struct {
-	handler Handler
-	fields  []Field
+	handler       Handler
+	fields        []Field
+	groups        []string // Set by WithGroup; later fields are nested under them
+	name          string   // Dot-separated name set by Named, emitted as the "logger" field
+	extractors    []ContextExtractor
+	duplicateKeys DuplicateKeyPolicy
+	location      *time.Location
+	state         *loggerState // Shared with child loggers
+	errorHandler  ErrorHandler
+	disabled      bool // Set by When(false); events are discarded
+	once          bool // Set by Once(); repeated messages are discarded
+	fingerprint   FingerprintFunc
+	clock         Clock
+	limits        Limits
+}
+
+// ErrorHandler is called when a handler fails to write an entry
+type ErrorHandler func(err error, entry Entry)
+
+// ContextExtractor returns fields taken from a context, such as trace IDs or baggage
+type ContextExtractor func(ctx context.Context) []Field
+
+// reportError sends a handler failure to the error handler, falling back to stderr
+func reportError(fn ErrorHandler, err error, entry Entry) {
+	metrics.writeErrors.Add(1)
+	metrics.lastError.Store(time.Now().UnixNano())
+	if fn != nil {
+		fn(err, entry)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "error writing log entry %q: %v\n", entry.Message, err)
+}
+
+// loggerState holds state shared by a logger and all loggers derived from it
+type loggerState struct {
+	shutdown    atomic.Bool
+	seenMu      sync.Mutex
+	seen        map[string]*list.Element // Level and message of entries logged through Once()
+	seenLRU     *list.List               // Most recently logged at the front
+	subscribers subscribers
+}
+
+// maxOnceMessages bounds the messages remembered for Once(); beyond it the least
+// recently logged ones are forgotten and may be written again
+const maxOnceMessages = 1024
+
+// firstTime reports whether level and msg are logged through Once() for the first time
+func (s *loggerState) firstTime(level Level, msg string) bool {
+	if s == nil {
+		return true
+	}
+	key := level.String() + "|" + msg
+
+	s.seenMu.Lock()
+	defer s.seenMu.Unlock()
+	if elem, ok := s.seen[key]; ok {
+		s.seenLRU.MoveToFront(elem)
+		return false
+	}
+	if s.seen == nil {
+		s.seen = make(map[string]*list.Element)
+		s.seenLRU = list.New()
+	}
+	s.seen[key] = s.seenLRU.PushFront(key)
+	if s.seenLRU.Len() > maxOnceMessages {
+		oldest := s.seenLRU.Back()
+		s.seenLRU.Remove(oldest)
+		delete(s.seen, oldest.Value.(string))
+	}
+	return true
 }
 
 // New creates a new logger with the provided handler
@@ -11,91 +93,306 @@ func New(handler Handler) *Logger {
 	return &Logger{
 		handler: handler,
 		fields:  make([]Field, 0),
+		state:   &loggerState{},
+	}
+}
+
+// Nop returns a logger that discards every event without allocating, e.g. as the
+// default logger of a library or to benchmark code without logging overhead
+// Its children (With, Named, ...) discard events too
+func Nop() *Logger {
+	return &Logger{
+		handler:  nopHandler{},
+		state:    &loggerState{},
+		disabled: true,
 	}
 }
 
+// nopHandler is the handler of Nop loggers; it handles nothing
+type nopHandler struct{}
+
+func (nopHandler) Enabled(Level) bool           { return false }
+func (nopHandler) Handle(Entry) error           { return nil }
+func (h nopHandler) WithFields([]Field) Handler { return h }
+
 // NewWithConfig creates a new logger with the provided configuration
+// If the log files cannot be opened, the error is printed to stderr and the logger
+// writes to the console instead; NewWithConfigE returns the error
 func NewWithConfig(cfg Config) *Logger {
+	handler, err := newConfigHandler(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error creating log handler, logging to console: %v\n", err)
+		handler = createConsoleHandler(cfg)
+	}
+	return newConfigLogger(cfg, handler)
+}
+
+// NewWithConfigE creates a new logger with the provided configuration, returning an
+// error when the config is invalid or the log files cannot be opened instead of
+// falling back to the console
+func NewWithConfigE(cfg Config) (*Logger, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	handler, err := newConfigHandler(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return newConfigLogger(cfg, handler), nil
+}
+
+// newConfigHandler creates the handler described by cfg
+func newConfigHandler(cfg Config) (Handler, error) {
 	var handler Handler
+	var err error
 
-	switch cfg.Output {
-	case OutputFile:
-		// Check rotation mode
-		if cfg.RotationMode == RotationDaily {
-			// Daily rotation based on date
-			baseDir := "./logs"
-			filePrefix := "" // No prefix by default (just date.log)
-
-			// Extract directory and optional prefix from OutputPath
-			if cfg.OutputPath != "" {
-				// If OutputPath ends with .log, it has a prefix
-				if len(cfg.OutputPath) > 4 && cfg.OutputPath[len(cfg.OutputPath)-4:] == ".log" {
-					// Extract directory and file prefix
-					dir, file := splitPath(cfg.OutputPath)
-					baseDir = dir
-					// Remove .log extension to get prefix
-					filePrefix = file[:len(file)-4]
-				} else {
-					// Just a directory path, no prefix
-					baseDir = cfg.OutputPath
-					filePrefix = "" // No prefix, just YYYY-MM-DD.log
-				}
-			}
+	switch {
+	case cfg.hasSections():
+		return createSectionHandler(cfg)
 
-			// Create daily file handler
-			// File should have no colors if MultiOutput is enabled (colors go to console)
-			// Otherwise, use the configured UseColor setting
-			fileUseColor := cfg.UseColor && !cfg.MultiOutput
-			dailyHandler, err := NewDailyFileHandler(
-				baseDir,
-				filePrefix,
-				cfg.Level,
-				cfg.MaxAge,
-				fileUseColor,
-				cfg.ColorConfig,
-			)
-			if err != nil {
-				// Fallback to console handler on error
-				handler = createConsoleHandler(cfg)
-			} else {
-				handler = dailyHandler
-			}
+	case cfg.Output == OutputFile:
+		if cfg.SplitLevelFiles {
+			handler, err = createLevelFilesHandler(cfg)
 		} else {
-			// Size-based rotation using lumberjack
-			handler = NewFileHandler(
-				cfg.OutputPath,
-				cfg.Level,
-				cfg.MaxSize,
-				cfg.MaxBackups,
-				cfg.MaxAge,
-				cfg.Compress,
-			)
+			handler, err = createFileHandler(cfg)
+		}
+		if err != nil {
+			return nil, err
 		}
 
 		// If multi-output is enabled, also log to console
 		if cfg.MultiOutput {
-			// Console handler with colors enabled
-			consoleHandler := NewConsoleHandlerWithConfig(cfg.Level, true, cfg.ColorConfig)
+			// Console handler with colors enabled (unless ColorMode says otherwise)
+			consoleLevel := cfg.legLevel(cfg.ConsoleLevel)
+			var consoleHandler Handler
+			if cfg.ConsoleFormat == FormatJSON {
+				consoleHandler = newJSONHandler(cfg.streamWriter(os.Stdout), consoleLevel, cfg.customFormatter(cfg.ConsoleFormat, cfg.newJSONFormatter(cfg.useColor(os.Stdout, true))))
+			} else {
+				h := newConsoleHandler(consoleLevel, cfg.customFormatter(cfg.ConsoleFormat, cfg.newConsoleFormatter(cfg.useColor(os.Stdout, true))))
+				h.writer = cfg.streamWriter(h.writer)
+				consoleHandler = h
+			}
 			handler = NewMultiHandler(handler, consoleHandler)
 		}
 
-	case OutputStdout, OutputStderr:
-		if cfg.Format == FormatJSON {
+	case cfg.Output == OutputStdout, cfg.Output == OutputStderr:
+		if cfg.SplitErrorOutput {
+			handler = createSplitHandler(cfg)
+		} else if cfg.Format == FormatJSON {
 			writer := cfg.getWriter()
-			handler = NewJSONHandler(writer, cfg.Level)
+			handler = newJSONHandler(cfg.streamWriter(writer), cfg.Level, cfg.customFormatter(cfg.Format, cfg.newJSONFormatter(cfg.useColor(writer, cfg.UseColor))))
+		} else if cfg.Format == FormatMsgpack || cfg.Format == FormatCBOR {
+			handler = newJSONHandler(cfg.streamWriter(cfg.getWriter()), cfg.Level, cfg.customFormatter(cfg.Format, cfg.newBinaryFormatter(cfg.Format)))
 		} else {
 			handler = createConsoleHandler(cfg)
 		}
 
 	default:
-		// Default to console handler
+		// A registered destination, otherwise the console
+		if factory, ok := registeredHandler(cfg.Output); ok {
+			return factory(cfg)
+		}
 		handler = createConsoleHandler(cfg)
 	}
+	return handler, nil
+}
+
+// newConfigLogger creates a logger writing to handler with the settings of cfg
+func newConfigLogger(cfg Config, handler Handler) *Logger {
+	logger := &Logger{
+		handler:       handler,
+		fields:        append([]Field(nil), cfg.DefaultFields...),
+		duplicateKeys: cfg.DuplicateKeys,
+		location:      cfg.Location,
+		state:         &loggerState{},
+		errorHandler:  cfg.ErrorHandler,
+		clock:         cfg.Clock,
+		extractors:    cfg.ContextExtractors,
+		limits:        cfg.Limits,
+	}
+	if cfg.AddFingerprint {
+		logger.fingerprint = DefaultFingerprint
+	}
+	if cfg.AddContainerID {
+		if id := ContainerID(); id != "" {
+			logger.fields = append(logger.fields, String("container_id", id))
+		}
+	}
+	return logger
+}
 
-	return &Logger{
-		handler: handler,
-		fields:  make([]Field, 0),
+// createFileHandler creates the rotating file handler described by cfg
+func createFileHandler(cfg Config) (Handler, error) {
+	// Check rotation mode
+	if cfg.RotationMode == RotationDaily || cfg.RotationMode == RotationHourly {
+		// Time-based rotation (daily or hourly)
+		baseDir := "./logs"
+		filePrefix := "" // No prefix by default (just date.log)
+
+		// Extract directory and optional prefix from OutputPath
+		if cfg.OutputPath != "" {
+			// If OutputPath ends with .log, it has a prefix
+			if len(cfg.OutputPath) > 4 && cfg.OutputPath[len(cfg.OutputPath)-4:] == ".log" {
+				// Extract directory and file prefix
+				dir, file := splitPath(cfg.OutputPath)
+				baseDir = dir
+				// Remove .log extension to get prefix
+				filePrefix = file[:len(file)-4]
+			} else {
+				// Just a directory path, no prefix
+				baseDir = cfg.OutputPath
+				filePrefix = "" // No prefix, just YYYY-MM-DD.log
+			}
+		}
+
+		dateLayout := dailyLayout
+		if cfg.RotationMode == RotationHourly {
+			dateLayout = hourlyLayout
+		}
+
+		// Create time rotating file handler
+		// File should have no colors if MultiOutput is enabled (colors go to console)
+		// Otherwise, use the configured UseColor setting
+		// Files are never terminals, so auto mode leaves them uncolored
+		fileUseColor := cfg.useColor(nil, cfg.UseColor) && !cfg.MultiOutput
+		// Time-based files follow Format, so JSON configs get JSON files; console text otherwise
+		timeFileFormat := cfg.Format
+		if timeFileFormat == "" {
+			timeFileFormat = FormatConsole
+		}
+		// Create the directory with the configured permissions before the handler does
+		if cfg.DirMode != 0 {
+			_ = os.MkdirAll(baseDir, cfg.DirMode)
+		}
+		dailyHandler, err := newTimeFileHandler(
+			baseDir,
+			filePrefix,
+			dateLayout,
+			cfg.legLevel(cfg.FileLevel),
+			cfg.MaxAge,
+			cfg.fileFormatter(timeFileFormat, fileUseColor),
+		)
+		if err != nil {
+			return nil, err
+		}
+		dailyHandler.SetLocation(cfg.Location)
+		dailyHandler.SetClock(cfg.Clock)
+		dailyHandler.SetMaxSize(cfg.MaxSize)
+		dailyHandler.SetMaxTotalSize(cfg.MaxTotalSize)
+		dailyHandler.SetCompress(cfg.Compress)
+		dailyHandler.SetFilenameTemplate(cfg.FilenameTemplate)
+		dailyHandler.SetFileLocking(cfg.FileLocking)
+		if cfg.SyncPolicy != "" {
+			dailyHandler.SetSyncPolicy(cfg.SyncPolicy, cfg.SyncInterval)
+		}
+		if err := dailyHandler.SetPermissions(cfg.FileMode, cfg.DirMode); err != nil {
+			fmt.Fprintf(os.Stderr, "error setting log permissions: %v\n", err)
+		}
+		dailyHandler.SetLatestLink(cfg.LatestLink)
+		dailyHandler.SetArchiver(cfg.Archiver, cfg.ArchiveDeleteLocal)
+		return dailyHandler, nil
+	}
+
+	// Lumberjack opens the file on the first write, so check it can be opened now
+	if err := checkFileWritable(cfg.OutputPath, cfg.FileMode, cfg.DirMode); err != nil {
+		return nil, err
+	}
+
+	// Size-based rotation using lumberjack
+	fileHandler := newFileHandler(
+		cfg.OutputPath,
+		cfg.legLevel(cfg.FileLevel),
+		cfg.MaxSize,
+		cfg.MaxBackups,
+		cfg.MaxAge,
+		cfg.Compress,
+		cfg.fileFormatter(FormatJSON, false),
+	)
+	// Lumberjack only distinguishes local time from UTC for backup names
+	fileHandler.rotator.LocalTime = cfg.Location != time.UTC
+	if cfg.SyncPolicy != "" {
+		fileHandler.SetSyncPolicy(cfg.SyncPolicy, cfg.SyncInterval)
+	}
+	if cfg.FileMode != 0 || cfg.DirMode != 0 {
+		if err := fileHandler.SetPermissions(cfg.FileMode, cfg.DirMode); err != nil {
+			fmt.Fprintf(os.Stderr, "error setting log permissions: %v\n", err)
+		}
 	}
+	return fileHandler, nil
+}
+
+// checkFileWritable creates path and its directory if needed and checks it can be opened for appending
+func checkFileWritable(path string, fileMode, dirMode os.FileMode) error {
+	if path == "" {
+		return errors.New("logpy: OutputPath is required for size-based rotation")
+	}
+	if fileMode == 0 {
+		fileMode = defaultFileMode
+	}
+	if dirMode == 0 {
+		dirMode = defaultDirMode
+	}
+	if err := os.MkdirAll(filepath.Dir(path), dirMode); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, fileMode)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	return f.Close()
+}
+
+// createLevelFilesHandler creates one file handler per built-in level at or above the
+// file level and routes each entry to the file of its level; custom levels go to the
+// file of the next lower built-in level
+func createLevelFilesHandler(cfg Config) (Handler, error) {
+	minLevel := cfg.legLevel(cfg.FileLevel)
+	if minLevel.AtLeast(ErrorLevel) {
+		minLevel = ErrorLevel
+	}
+
+	var handler Handler
+	upper := ErrorLevel
+	for i := len(builtinLevels) - 1; i >= 0; i-- {
+		level := builtinLevels[i]
+		legCfg := cfg
+		legCfg.Level = level
+		if !level.AtLeast(minLevel) {
+			legCfg.Level = minLevel
+		}
+		legCfg.FileLevel = nil
+		legCfg.OutputPath = levelFilePath(cfg.OutputPath, level)
+		leg, err := createFileHandler(legCfg)
+		if err != nil {
+			if handler != nil {
+				closeHandler(handler)
+			}
+			return nil, err
+		}
+		if handler == nil {
+			handler = leg
+		} else {
+			handler = NewLevelRouterHandler(upper, leg, handler)
+		}
+		if minLevel.AtLeast(level) {
+			break
+		}
+		upper = level
+	}
+	return handler, nil
+}
+
+// levelFilePath returns the file path for level: "logs" becomes "logs/error.log"
+// and "logs/app.log" becomes "logs/app-error.log"
+func levelFilePath(path string, level Level) string {
+	name := strings.ToLower(level.String())
+	if path == "" {
+		path = "./logs"
+	}
+	if strings.HasSuffix(path, ".log") {
+		return strings.TrimSuffix(path, ".log") + "-" + name + ".log"
+	}
+	return filepath.Join(path, name+".log")
 }
 
 // splitPath splits a file path into directory and filename
@@ -110,7 +407,22 @@ func splitPath(path string) (dir, file string) {
 
 // createConsoleHandler is a helper to create a console handler from config
 func createConsoleHandler(cfg Config) Handler {
-	return NewConsoleHandlerWithConfig(cfg.Level, cfg.UseColor, cfg.ColorConfig)
+	h := newConsoleHandler(cfg.Level, cfg.customFormatter(cfg.Format, cfg.newConsoleFormatter(cfg.useColor(os.Stdout, cfg.UseColor))))
+	h.writer = cfg.streamWriter(h.writer)
+	return h
+}
+
+// createStreamHandler creates a console, JSON or binary handler (per cfg.Format) writing to f
+func createStreamHandler(cfg Config, f *os.File) Handler {
+	switch cfg.Format {
+	case FormatJSON:
+		return newJSONHandler(cfg.streamWriter(f), cfg.Level, cfg.customFormatter(cfg.Format, cfg.newJSONFormatter(cfg.useColor(f, cfg.UseColor))))
+	case FormatMsgpack, FormatCBOR:
+		return newJSONHandler(cfg.streamWriter(f), cfg.Level, cfg.customFormatter(cfg.Format, cfg.newBinaryFormatter(cfg.Format)))
+	}
+	h := newConsoleHandler(cfg.Level, cfg.customFormatter(cfg.Format, cfg.newConsoleFormatter(cfg.useColor(f, cfg.UseColor))))
+	h.writer = cfg.streamWriter(consoleWriter(f))
+	return h
 }
 
 // Default creates a logger with default configuration
@@ -129,15 +441,208 @@ func Production() *Logger {
 }
 
 // With creates a child logger with additional persistent fields
+// With the default keep-last duplicate key policy, a field replaces the parent's field
+// with the same key instead of accumulating; other policies keep both until an entry is written
 func (l *Logger) With(fields ...Field) *Logger {
+	override := l.duplicateKeys == "" || l.duplicateKeys == DuplicateKeepLast
+	if len(l.groups) > 0 || override {
+		child := *l
+		child.fields = mergeGroups(l.fields, nestFields(l.groups, fields), override)
+		return &child
+	}
+
 	newFields := make([]Field, 0, len(l.fields)+len(fields))
 	newFields = append(newFields, l.fields...)
 	newFields = append(newFields, fields...)
 
-	return &Logger{
-		handler: l.handler,
-		fields:  newFields,
+	child := *l
+	child.fields = newFields
+	return &child
+}
+
+// WithMap creates a child logger with persistent fields from m, see FieldsFromMap
+func (l *Logger) WithMap(m map[string]interface{}) *Logger {
+	return l.With(FieldsFromMap(m)...)
+}
+
+// WithContextExtractor creates a child logger that also applies fns in Ctx
+func (l *Logger) WithContextExtractor(fns ...ContextExtractor) *Logger {
+	child := *l
+	child.extractors = append(append(make([]ContextExtractor, 0, len(l.extractors)+len(fns)), l.extractors...), fns...)
+	return &child
+}
+
+// Ctx returns a child logger with the fields the context extractors take from ctx
+// Example: logger.Ctx(r.Context()).Info().Msg("handled")
+func (l *Logger) Ctx(ctx context.Context) *Logger {
+	if ctx == nil || len(l.extractors) == 0 {
+		return l
 	}
+	var fields []Field
+	for _, fn := range l.extractors {
+		fields = append(fields, fn(ctx)...)
+	}
+	if len(fields) == 0 {
+		return l
+	}
+	return l.With(fields...)
+}
+
+// Without creates a child logger without the context fields named by keys
+// Fields inside groups are named by their dotted key, e.g. "db.host"
+// Example: retryLog := reqLog.Without("attempt").With(logpy.Int("attempt", n))
+func (l *Logger) Without(keys ...string) *Logger {
+	if len(keys) == 0 {
+		return l
+	}
+	remove := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		remove[key] = true
+	}
+	child := *l
+	child.fields = removeFields(l.fields, remove, "")
+	return &child
+}
+
+// WithGroup creates a child logger whose later fields, from With and from events,
+// are nested under name: an object in JSON and a "name." key prefix in console output
+// Example: logger.WithGroup("db").With(logpy.String("host", host)).Info().Int("rows", n).Msg("query")
+func (l *Logger) WithGroup(name string) *Logger {
+	if name == "" {
+		return l
+	}
+	child := *l
+	child.groups = append(append(make([]string, 0, len(l.groups)+1), l.groups...), name)
+	return &child
+}
+
+// Named creates a child logger whose name is the parent's name followed by "." and name
+// Entries carry the full name in a "logger" field so components can be filtered
+// Example: logger.Named("payments").Named("stripe") logs logger=payments.stripe
+func (l *Logger) Named(name string) *Logger {
+	if name == "" {
+		return l
+	}
+	child := *l
+	if l.name != "" {
+		child.name = l.name + "." + name
+	} else {
+		child.name = name
+	}
+	return &child
+}
+
+// Name returns the logger name set by Named
+func (l *Logger) Name() string {
+	return l.name
+}
+
+// When returns the logger if cond is true and otherwise a logger that discards every event
+// Example: logger.When(verbose).Debug().Any("state", state).Msg("dump")
+func (l *Logger) When(cond bool) *Logger {
+	if cond {
+		return l
+	}
+	child := *l
+	child.disabled = true
+	return &child
+}
+
+// Once returns a logger that writes each distinct level and message only once
+// The record of seen messages is shared with the parent logger and its children and
+// keeps the 1024 most recently logged ones, so messages with variable text such as IDs
+// cannot grow it without bound; Once is meant for fixed messages
+// Example: logger.Once().Warn().Msg("config option X is deprecated")
+func (l *Logger) Once() *Logger {
+	child := *l
+	child.once = true
+	return &child
+}
+
+// WithFingerprint creates a child logger that adds a "fingerprint" field computed by fn
+// to every entry so downstream tools can group similar entries (nil removes the field)
+func (l *Logger) WithFingerprint(fn FingerprintFunc) *Logger {
+	child := *l
+	child.fingerprint = fn
+	return &child
+}
+
+// WithClock creates a child logger that takes entry timestamps from clock (nil uses time.Now)
+func (l *Logger) WithClock(clock Clock) *Logger {
+	child := *l
+	child.clock = clock
+	return &child
+}
+
+// WithLimits creates a child logger that caps message, field and entry sizes
+func (l *Logger) WithLimits(limits Limits) *Logger {
+	child := *l
+	child.limits = limits
+	return &child
+}
+
+// WithDuplicateKeys creates a child logger that resolves repeated field keys using policy
+func (l *Logger) WithDuplicateKeys(policy DuplicateKeyPolicy) *Logger {
+	child := *l
+	child.duplicateKeys = policy
+	return &child
+}
+
+// WithErrorHandler creates a child logger that reports write failures to fn
+// A nil fn restores the default of printing failures to stderr
+func (l *Logger) WithErrorHandler(fn ErrorHandler) *Logger {
+	child := *l
+	child.errorHandler = fn
+	return &child
+}
+
+// Flush flushes buffered output in all handlers
+// Call it before the program exits so no entries are lost
+func (l *Logger) Flush() error {
+	if f, ok := l.handler.(Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// Close flushes and closes all handlers, releasing files and background workers
+// The logger must not be used after Close
+func (l *Logger) Close() error {
+	if c, ok := l.handler.(io.Closer); ok {
+		return c.Close()
+	}
+	return l.Flush()
+}
+
+// Shutdown stops the logger and all loggers derived from it from accepting new
+// entries, drains asynchronous handlers until ctx is done, and closes the handlers
+// It returns the number of queued entries that could not be delivered in time
+// When ctx is done first it returns without waiting for deliveries in flight
+func (l *Logger) Shutdown(ctx context.Context) (int, error) {
+	if l.state != nil {
+		l.state.shutdown.Store(true)
+	}
+
+	var dropped int
+	var errs []error
+	if d, ok := l.handler.(Drainer); ok {
+		n, err := d.Drain(ctx)
+		dropped = n
+		errs = append(errs, err)
+	}
+	errs = append(errs, l.Close())
+	return dropped, errors.Join(errs...)
+}
+
+// isShutdown reports whether Shutdown has been called on the logger or its parent
+func (l *Logger) isShutdown() bool {
+	return l.state != nil && l.state.shutdown.Load()
+}
+
+// Handler returns the handler the logger writes to, e.g. to share it with an adapter
+// for another logging library
+func (l *Logger) Handler() Handler {
+	return l.handler
 }
 
 // Debug creates a debug level event
@@ -160,6 +665,17 @@ func (l *Logger) Error() *Event {
 	return newEvent(l, ErrorLevel)
 }
 
+// Log creates an event at level, e.g. a level added with RegisterLevel
+func (l *Logger) Log(level Level) *Event {
+	return newEvent(l, level)
+}
+
+// Track starts timing op and returns a function that logs op at Info level with a
+// "duration" field when called: defer logpy.Track(logger, "rebuild_index")()
+func Track(logger *Logger, op string) func() {
+	return logger.Info().Timer(op)
+}
+
 // Global logger instance
 var global = Default()
 
@@ -173,6 +689,11 @@ func Global() *Logger {
 	return global
 }
 
+// Shutdown shuts down the global logger, see Logger.Shutdown
+func Shutdown(ctx context.Context) (int, error) {
+	return global.Shutdown(ctx)
+}
+
 // Log provides direct access to the global logger for quick logging
 // Example: logpy.Log().Info().Str("key", "value").Msg("message")
 func Log() *Logger {
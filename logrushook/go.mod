@@ -0,0 +1,13 @@
+module github.com/nhatpy/logpy/logrushook
+
+go 1.25.0
+
+require (
+	github.com/nhatpy/logpy v0.0.0-20261016204738-105601b6e2d3
+	github.com/sirupsen/logrus v1.10.2
+)
+
+require (
+	golang.org/x/sys v0.47.0 // indirect
+	gopkg.in/natefinch/lumberjack.v2 v2.2.1 // indirect
+)
@@ -0,0 +1,81 @@
+// Package logrushook forwards logrus entries to a logpy logger, so a large logrus
+// codebase can move to logpy package by package while all output goes through logpy.
+//
+// Example:
+//
+//	logrus.AddHook(logrushook.New(logger))
+//	logrus.SetOutput(io.Discard) // Let logpy write every entry once
+//
+//	logrus.WithField("user", "alice").Warn("quota exceeded") // Written by logpy
+package logrushook
+
+import (
+	"path/filepath"
+
+	"github.com/nhatpy/logpy"
+	"github.com/sirupsen/logrus"
+)
+
+// Hook is a logrus.Hook that writes entries to a logpy logger
+// Entry data become fields; the time of the logrus entry is kept
+type Hook struct {
+	logger *logpy.Logger
+	levels []logrus.Level
+}
+
+// New creates a hook that forwards entries of every level to logger
+func New(logger *logpy.Logger) *Hook {
+	return &Hook{logger: logger, levels: logrus.AllLevels}
+}
+
+// SetLevels limits the logrus levels the hook receives (default all)
+func (h *Hook) SetLevels(levels ...logrus.Level) {
+	if len(levels) == 0 {
+		levels = logrus.AllLevels
+	}
+	h.levels = levels
+}
+
+// Levels implements logrus.Hook
+func (h *Hook) Levels() []logrus.Level {
+	return h.levels
+}
+
+// Fire implements logrus.Hook
+// logrus callers are several frames away from the hook, so the caller is only recorded
+// when logrus reports it (SetReportCaller). logrus exits or panics after firing hooks
+// for Fatal and Panic entries, so the logger is flushed first
+func (h *Hook) Fire(entry *logrus.Entry) error {
+	event := h.logger.Log(Level(entry.Level))
+	if entry.Caller != nil {
+		event = event.Caller(logpy.CallerInfo{
+			File:     filepath.Base(entry.Caller.File),
+			Line:     entry.Caller.Line,
+			Function: entry.Caller.Function,
+		})
+	} else {
+		event = event.NoCaller()
+	}
+	event.Ts(entry.Time).
+		FieldsMap(entry.Data).
+		Msg(entry.Message)
+
+	if entry.Level <= logrus.FatalLevel {
+		return h.logger.Flush()
+	}
+	return nil
+}
+
+// Level converts a logrus level to a logpy level; trace becomes Debug, fatal and panic Error
+func Level(level logrus.Level) logpy.Level {
+	switch level {
+	case logrus.TraceLevel, logrus.DebugLevel:
+		return logpy.DebugLevel
+	case logrus.InfoLevel:
+		return logpy.InfoLevel
+	case logrus.WarnLevel:
+		return logpy.WarnLevel
+	default:
+		return logpy.ErrorLevel
+	}
+}
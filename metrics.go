@@ -0,0 +1,107 @@
+package logpy
+
+import (
+	"expvar"
+	"sync/atomic"
+	"time"
+)
+
+// MetricsSnapshot is a point-in-time copy of the process-wide logging counters
+type MetricsSnapshot struct {
+	Entries       map[Level]uint64 // Entries logged, by level
+	WriteErrors   uint64           // Entries a handler failed to write
+	LastErrorTime time.Time        // Time of the last write error (zero if none)
+	Dropped       uint64           // Entries dropped by asynchronous handlers
+	QueueDepth    int64            // Entries currently queued in asynchronous handlers
+	BytesWritten  uint64           // Bytes written by the built-in handlers
+	Rotations     uint64           // File rotations, by time or size
+}
+
+// FileStats is a snapshot of the counters of a file handler, for health endpoints
+type FileStats struct {
+	BytesWritten   uint64 // Bytes written since the handler was created
+	EntriesWritten uint64 // Entries written without error
+	Rotations      uint64 // Files rotated out, by time or size
+	WriteErrors    uint64 // Entries that failed to write
+	Path           string // Current log file ("" before the first write to a daily file)
+	Size           int64  // Size of the current file in bytes
+}
+
+// handlerStats holds the counters of one handler
+type handlerStats struct {
+	bytes       atomic.Uint64
+	entries     atomic.Uint64
+	rotations   atomic.Uint64
+	writeErrors atomic.Uint64
+}
+
+// snapshot copies the counters into FileStats
+func (s *handlerStats) snapshot() FileStats {
+	return FileStats{
+		BytesWritten:   s.bytes.Load(),
+		EntriesWritten: s.entries.Load(),
+		Rotations:      s.rotations.Load(),
+		WriteErrors:    s.writeErrors.Load(),
+	}
+}
+
+// metrics holds the counters updated by loggers and handlers
+var metrics struct {
+	entries      [256]atomic.Uint64 // Indexed by uint8(level), so custom levels are counted too
+	writeErrors  atomic.Uint64
+	lastError    atomic.Int64 // Unix nanoseconds
+	dropped      atomic.Uint64
+	queueDepth   atomic.Int64
+	bytesWritten atomic.Uint64
+	rotations    atomic.Uint64
+}
+
+// ReadMetrics returns the current logging counters for all loggers in the process
+// See the promlog package for a Prometheus collector
+func ReadMetrics() MetricsSnapshot {
+	snap := MetricsSnapshot{
+		Entries:      make(map[Level]uint64, len(builtinLevels)),
+		WriteErrors:  metrics.writeErrors.Load(),
+		Dropped:      metrics.dropped.Load(),
+		QueueDepth:   metrics.queueDepth.Load(),
+		BytesWritten: metrics.bytesWritten.Load(),
+		Rotations:    metrics.rotations.Load(),
+	}
+	if ns := metrics.lastError.Load(); ns != 0 {
+		snap.LastErrorTime = time.Unix(0, ns)
+	}
+	for _, level := range Levels() {
+		snap.Entries[level] = metrics.entries[uint8(level)].Load()
+	}
+	return snap
+}
+
+// PublishExpvar publishes the logging counters as an expvar variable, so they
+// appear under name in /debug/vars. Like expvar.Publish it panics if name is taken
+func PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		snap := ReadMetrics()
+		entries := make(map[string]uint64, len(snap.Entries))
+		for level, n := range snap.Entries {
+			entries[level.String()] = n
+		}
+		var lastError string
+		if !snap.LastErrorTime.IsZero() {
+			lastError = snap.LastErrorTime.Format(time.RFC3339Nano)
+		}
+		return map[string]interface{}{
+			"entries":         entries,
+			"write_errors":    snap.WriteErrors,
+			"last_error_time": lastError,
+			"dropped":         snap.Dropped,
+			"queue_depth":     snap.QueueDepth,
+			"bytes_written":   snap.BytesWritten,
+			"rotations":       snap.Rotations,
+		}
+	}))
+}
+
+// countEntry records a logged entry
+func countEntry(level Level) {
+	metrics.entries[uint8(level)].Add(1)
+}
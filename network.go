@@ -0,0 +1,222 @@
+package logpy
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+const defaultDialTimeout = 10 * time.Second
+
+// TLSOptions describes a TLS or mutual TLS connection to a log collector
+type TLSOptions struct {
+	CAFile             string // PEM CA bundle to verify the server (system roots when empty)
+	CertFile           string // PEM client certificate for mutual TLS
+	KeyFile            string // PEM client key for mutual TLS
+	ServerName         string // Overrides the name checked against the server certificate
+	InsecureSkipVerify bool   // Skip server verification (testing only)
+}
+
+// Config builds a tls.Config from the options
+func (o TLSOptions) Config() (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         o.ServerName,
+		InsecureSkipVerify: o.InsecureSkipVerify,
+	}
+
+	if o.CAFile != "" {
+		pem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", o.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if o.CertFile != "" || o.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// HTTPClient returns an HTTP client using the options, e.g. for archive uploaders
+func (o TLSOptions) HTTPClient(timeout time.Duration) (*http.Client, error) {
+	cfg, err := o.Config()
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = cfg
+	return &http.Client{Transport: transport, Timeout: timeout}, nil
+}
+
+// HTTPOptions configures authentication, proxying and TLS for HTTP log endpoints
+type HTTPOptions struct {
+	BearerToken string            // Sent as "Authorization: Bearer <token>"
+	Username    string            // Basic auth user, used when BearerToken is empty
+	Password    string            // Basic auth password
+	Headers     map[string]string // Extra headers, e.g. an API key header
+	ProxyURL    string            // HTTP proxy; empty uses the environment (HTTP_PROXY, ...)
+	TLS         TLSOptions
+	Timeout     time.Duration // Request timeout (default 30s)
+}
+
+// Client returns an HTTP client that adds the configured credentials to every request
+func (o HTTPOptions) Client() (*http.Client, error) {
+	timeout := o.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	client, err := o.TLS.HTTPClient(timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := client.Transport.(*http.Transport)
+	if o.ProxyURL != "" {
+		proxy, err := url.Parse(o.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	client.Transport = &authTransport{base: transport, options: o}
+	return client, nil
+}
+
+// authTransport adds credentials and headers to outgoing requests
+type authTransport struct {
+	base    http.RoundTripper
+	options HTTPOptions
+}
+
+// RoundTrip implements http.RoundTripper
+// Headers the request already has are kept, so signed requests such as S3 uploads
+// keep their Authorization header
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTrippers must not modify the caller's request
+	req = req.Clone(req.Context())
+	for key, value := range t.options.Headers {
+		if req.Header.Get(key) == "" {
+			req.Header.Set(key, value)
+		}
+	}
+	if req.Header.Get("Authorization") == "" {
+		switch {
+		case t.options.BearerToken != "":
+			req.Header.Set("Authorization", "Bearer "+t.options.BearerToken)
+		case t.options.Username != "" || t.options.Password != "":
+			req.SetBasicAuth(t.options.Username, t.options.Password)
+		}
+	}
+	return t.base.RoundTrip(req)
+}
+
+// NetWriter writes to a TCP, TLS, UDP or Unix socket collector, dialing lazily and
+// reconnecting after a failed write. Use it as the writer of a JSON or console handler
+type NetWriter struct {
+	network   string
+	addr      string
+	tlsConfig *tls.Config
+	timeout   time.Duration
+
+	mu     sync.Mutex
+	conn   net.Conn
+	closed bool
+}
+
+// NewNetWriter creates a writer for addr on network ("tcp", "udp", "unix", ...)
+// A non-nil tlsConfig wraps stream connections in TLS
+func NewNetWriter(network, addr string, tlsConfig *tls.Config) *NetWriter {
+	return &NetWriter{
+		network:   network,
+		addr:      addr,
+		tlsConfig: tlsConfig,
+		timeout:   defaultDialTimeout,
+	}
+}
+
+// SetTimeout sets the dial and write timeout
+func (w *NetWriter) SetTimeout(d time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if d <= 0 {
+		d = defaultDialTimeout
+	}
+	w.timeout = d
+}
+
+// dial opens a new connection
+func (w *NetWriter) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: w.timeout}
+	if w.tlsConfig != nil {
+		return tls.DialWithDialer(dialer, w.network, w.addr, w.tlsConfig)
+	}
+	return dialer.Dial(w.network, w.addr)
+}
+
+// Write implements io.Writer
+// A write that fails on an existing connection before sending anything is retried
+// once on a new one; after a partial write the entry is not resent, since the
+// collector would receive a truncated line followed by a duplicate
+func (w *NetWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, ErrHandlerClosed
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < 2; attempt++ {
+		if w.conn == nil {
+			conn, err := w.dial()
+			if err != nil {
+				return 0, err
+			}
+			w.conn = conn
+		}
+		w.conn.SetWriteDeadline(time.Now().Add(w.timeout))
+		n, err := w.conn.Write(p)
+		if err == nil {
+			return n, nil
+		}
+		w.conn.Close()
+		w.conn = nil
+		if n > 0 {
+			return n, err
+		}
+		lastErr = err
+	}
+	return 0, lastErr
+}
+
+// Close closes the connection
+func (w *NetWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	if errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,143 @@
+package logpy
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultNonBlockingBuffer       = 256
+	defaultNonBlockingFlushTimeout = time.Second
+)
+
+// nonBlockingItem is either a line to write or a flush marker
+type nonBlockingItem struct {
+	data    []byte
+	flushed chan struct{} // Non-nil for flush markers
+}
+
+// NonBlockingWriter writes to an underlying writer from a background goroutine and
+// never blocks the caller. When the writer is backpressured (e.g. a stalled Docker log
+// driver on stdout) and the buffer is full, lines are dropped and counted in Dropped
+// and the process-wide Dropped metric instead of freezing the application
+type NonBlockingWriter struct {
+	w            io.Writer
+	queue        chan nonBlockingItem
+	done         chan struct{}
+	mu           sync.RWMutex
+	closed       bool
+	flushTimeout time.Duration
+	dropped      atomic.Int64
+}
+
+// NewNonBlockingWriter creates a writer with room for bufferSize queued lines (default 256)
+func NewNonBlockingWriter(w io.Writer, bufferSize int) *NonBlockingWriter {
+	if bufferSize <= 0 {
+		bufferSize = defaultNonBlockingBuffer
+	}
+
+	nb := &NonBlockingWriter{
+		w:            w,
+		queue:        make(chan nonBlockingItem, bufferSize),
+		done:         make(chan struct{}),
+		flushTimeout: defaultNonBlockingFlushTimeout,
+	}
+	go nb.run()
+	return nb
+}
+
+// run writes queued lines until the queue is closed
+func (nb *NonBlockingWriter) run() {
+	defer close(nb.done)
+	for item := range nb.queue {
+		if item.flushed != nil {
+			close(item.flushed)
+			continue
+		}
+		nb.w.Write(item.data) // Nobody is waiting for the result
+	}
+}
+
+// SetFlushTimeout sets how long Flush waits for queued lines (default 1s), so a
+// stalled writer cannot block shutdown
+func (nb *NonBlockingWriter) SetFlushTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = defaultNonBlockingFlushTimeout
+	}
+	nb.mu.Lock()
+	nb.flushTimeout = timeout
+	nb.mu.Unlock()
+}
+
+// Write queues a copy of p, dropping it if the buffer is full
+// It always reports success so a full buffer is not treated as a write error
+func (nb *NonBlockingWriter) Write(p []byte) (int, error) {
+	nb.mu.RLock()
+	defer nb.mu.RUnlock()
+	if nb.closed {
+		return 0, ErrHandlerClosed
+	}
+
+	select {
+	case nb.queue <- nonBlockingItem{data: append([]byte(nil), p...)}:
+	default:
+		nb.dropped.Add(1)
+		metrics.dropped.Add(1)
+	}
+	return len(p), nil
+}
+
+// Dropped returns the number of lines dropped because the buffer was full
+func (nb *NonBlockingWriter) Dropped() int64 {
+	return nb.dropped.Load()
+}
+
+// Flush waits until the lines queued so far are written, or the flush timeout passes
+func (nb *NonBlockingWriter) Flush() error {
+	nb.mu.RLock()
+	if nb.closed {
+		nb.mu.RUnlock()
+		return nil
+	}
+	timer := time.NewTimer(nb.flushTimeout)
+	defer timer.Stop()
+
+	marker := nonBlockingItem{flushed: make(chan struct{})}
+	select {
+	case nb.queue <- marker:
+		nb.mu.RUnlock()
+	case <-timer.C:
+		nb.mu.RUnlock()
+		return ErrHandlerTimeout
+	}
+
+	select {
+	case <-marker.flushed:
+		return nil
+	case <-timer.C:
+		return ErrHandlerTimeout
+	}
+}
+
+// Close writes the queued lines, waiting at most the flush timeout, and stops the
+// background goroutine. The underlying writer is left open
+func (nb *NonBlockingWriter) Close() error {
+	nb.mu.Lock()
+	if nb.closed {
+		nb.mu.Unlock()
+		return nil
+	}
+	nb.closed = true
+	close(nb.queue)
+	timeout := nb.flushTimeout
+	nb.mu.Unlock()
+
+	select {
+	case <-nb.done:
+		return nil
+	case <-time.After(timeout):
+		return ErrHandlerTimeout
+	}
+}
@@ -0,0 +1,130 @@
+package logpy
+
+// Option configures a logger created by NewWithOptions
+// It edits the Config the logger is built from, so custom options are plain functions
+type Option func(*Config)
+
+// NewWithOptions creates a logger from options, so common setups fit on one line:
+//
+//	logger := logpy.NewWithOptions(logpy.WithConsole(), logpy.WithFile("./logs"), logpy.WithDailyRotation(14))
+//
+// It starts from Info level with caller info and writes to the console unless an
+// option chooses another destination. WithJSON applies to every destination
+// whose format is not set explicitly, whatever the order of the options
+func NewWithOptions(opts ...Option) *Logger {
+	cfg := Config{
+		Level:       InfoLevel,
+		ColorConfig: DefaultColorConfig(),
+		AddCaller:   true,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if !cfg.hasSections() {
+		cfg.Console = &ConsoleConfig{}
+	}
+	if cfg.Format != "" {
+		if cfg.Console != nil && cfg.Console.Format == "" {
+			cfg.Console.Format = cfg.Format
+		}
+		if cfg.File != nil && cfg.File.Format == "" {
+			cfg.File.Format = cfg.Format
+		}
+		if cfg.Remote != nil && cfg.Remote.Format == "" {
+			cfg.Remote.Format = cfg.Format
+		}
+	}
+	return NewWithConfig(cfg)
+}
+
+// WithLevel sets the minimum level of every destination without its own level
+func WithLevel(level Level) Option {
+	return func(cfg *Config) {
+		cfg.Level = level
+	}
+}
+
+// WithJSON writes JSON lines to every destination without its own format
+func WithJSON() Option {
+	return func(cfg *Config) {
+		cfg.Format = FormatJSON
+	}
+}
+
+// WithConsole writes to stdout, colored on a terminal
+func WithConsole() Option {
+	return func(cfg *Config) {
+		consoleSection(cfg)
+	}
+}
+
+// WithConsoleLevel writes to stdout at its own minimum level
+func WithConsoleLevel(level Level) Option {
+	return func(cfg *Config) {
+		consoleSection(cfg).Level = level.Ptr()
+	}
+}
+
+// WithFile writes to daily files under path, a directory or "dir/prefix.log"
+func WithFile(path string) Option {
+	return func(cfg *Config) {
+		fileSection(cfg).Path = path
+	}
+}
+
+// WithFileLevel writes files at their own minimum level
+func WithFileLevel(level Level) Option {
+	return func(cfg *Config) {
+		fileSection(cfg).Level = level.Ptr()
+	}
+}
+
+// WithDailyRotation rotates files daily and keeps them for days (default ./logs)
+func WithDailyRotation(days int) Option {
+	return func(cfg *Config) {
+		sec := fileSection(cfg)
+		sec.Rotation = RotationDaily
+		sec.MaxAge = days
+	}
+}
+
+// WithSizeRotation rotates files at maxMB megabytes and keeps backups old files
+func WithSizeRotation(maxMB, backups int) Option {
+	return func(cfg *Config) {
+		sec := fileSection(cfg)
+		sec.Rotation = RotationSize
+		sec.MaxSize = maxMB
+		sec.MaxBackups = backups
+	}
+}
+
+// WithRemote sends entries to a collector over network ("tcp", "udp", "unix")
+func WithRemote(network, address string) Option {
+	return func(cfg *Config) {
+		cfg.Remote = &RemoteConfig{Network: network, Address: address}
+	}
+}
+
+// WithFields adds context fields to every entry, e.g. service and env
+func WithFields(fields ...Field) Option {
+	return func(cfg *Config) {
+		cfg.DefaultFields = append(cfg.DefaultFields, fields...)
+	}
+}
+
+// consoleSection returns the console section of cfg, creating it if needed
+func consoleSection(cfg *Config) *ConsoleConfig {
+	if cfg.Console == nil {
+		cfg.Console = &ConsoleConfig{}
+	}
+	return cfg.Console
+}
+
+// fileSection returns the file section of cfg, creating it if needed
+func fileSection(cfg *Config) *FileConfig {
+	if cfg.File == nil {
+		cfg.File = &FileConfig{}
+	}
+	return cfg.File
+}
@@ -0,0 +1,10 @@
+module github.com/nhatpy/logpy/otelbaggage
+
+go 1.25.0
+
+require (
+	github.com/nhatpy/logpy v0.0.0-20261016204738-105601b6e2d3
+	go.opentelemetry.io/otel v1.44.0
+)
+
+require gopkg.in/natefinch/lumberjack.v2 v2.2.1 // indirect
@@ -0,0 +1,56 @@
+// Package otelbaggage copies OpenTelemetry baggage members from a context into log
+// fields, so attributes set at the edge (tenant, experiment flags) appear on every
+// downstream log line.
+//
+// Example:
+//
+//	cfg := logpy.ProductionConfig()
+//	cfg.ContextExtractors = []logpy.ContextExtractor{otelbaggage.Extractor("tenant", "experiment")}
+//	logger := logpy.NewWithConfig(cfg)
+//
+//	logger.Ctx(ctx).Info().Msg("order created") // tenant=acme experiment=new-checkout
+package otelbaggage
+
+import (
+	"context"
+	"sort"
+
+	"github.com/nhatpy/logpy"
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// Extractor returns a context extractor that copies the baggage members named by
+// keys into string fields with the same keys. With no keys every member is copied
+func Extractor(keys ...string) logpy.ContextExtractor {
+	return func(ctx context.Context) []logpy.Field {
+		return Fields(ctx, keys...)
+	}
+}
+
+// Fields returns the baggage members in ctx named by keys as string fields, in key
+// order. Missing members are skipped. With no keys every member is returned, sorted by key
+func Fields(ctx context.Context, keys ...string) []logpy.Field {
+	bag := baggage.FromContext(ctx)
+	if bag.Len() == 0 {
+		return nil
+	}
+
+	if len(keys) == 0 {
+		members := bag.Members()
+		sort.Slice(members, func(i, j int) bool { return members[i].Key() < members[j].Key() })
+		fields := make([]logpy.Field, len(members))
+		for i, member := range members {
+			fields[i] = logpy.String(member.Key(), member.Value())
+		}
+		return fields
+	}
+
+	fields := make([]logpy.Field, 0, len(keys))
+	for _, key := range keys {
+		// Member returns an empty member when key is absent
+		if member := bag.Member(key); member.Key() != "" {
+			fields = append(fields, logpy.String(key, member.Value()))
+		}
+	}
+	return fields
+}
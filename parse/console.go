@@ -0,0 +1,177 @@
+package parse
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nhatpy/logpy"
+)
+
+// decodeConsole decodes a console line and any multi-line value blocks below it
+// Field values are returned as strings since console output does not keep types.
+// Text that looks like key=value pairs at the end of a message is read as fields
+func (d *Decoder) decodeConsole(line string) (logpy.Entry, error) {
+	var entry logpy.Entry
+
+	// Timestamp: "[2006-01-02 15:04:05]"
+	if !strings.HasPrefix(line, "[") {
+		return entry, d.syntaxError("missing timestamp")
+	}
+	end := strings.IndexByte(line, ']')
+	if end < 0 {
+		return entry, d.syntaxError("unterminated timestamp")
+	}
+	t, err := time.ParseInLocation(d.timestampFormat, line[1:end], d.location)
+	if err != nil {
+		return entry, d.syntaxError("invalid timestamp %q", line[1:end])
+	}
+	entry.Time = t
+
+	// Level, padded to five characters
+	rest := strings.TrimLeft(line[end+1:], " ")
+	levelText, rest, _ := strings.Cut(rest, " ")
+	level, ok := d.parseLevel(levelText)
+	if !ok {
+		return entry, d.syntaxError("invalid level %q", levelText)
+	}
+	entry.Level = level
+	rest = strings.TrimLeft(rest, " ")
+
+	// Optional caller "file:line"
+	if token, after, _ := strings.Cut(rest, " "); !strings.Contains(token, "=") {
+		if caller, ok := parseCaller(token); ok {
+			entry.Caller = caller
+			rest = after
+		}
+	}
+
+	// The message is everything before the longest suffix made only of fields
+	entry.Message = rest
+	for i := 0; i <= len(rest); i++ {
+		if i > 0 && rest[i-1] != ' ' {
+			continue
+		}
+		fields, contextFields, ok := parseFields(rest[i:])
+		if ok {
+			entry.Message = strings.TrimRight(rest[:i], " ")
+			entry.Fields = fields
+			entry.ContextFields = contextFields
+			break
+		}
+	}
+
+	// Multi-line values follow as indented "key:" headers with doubly indented lines
+	for {
+		next, err := d.readLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return entry, err
+		}
+		next = stripANSI(next)
+		if !strings.HasPrefix(next, multilineIndent) || strings.HasPrefix(next, multilineIndent+multilineIndent) ||
+			!strings.HasSuffix(next, ":") {
+			d.unreadLine(next)
+			break
+		}
+		key := strings.TrimSuffix(strings.TrimPrefix(next, multilineIndent), ":")
+
+		var lines []string
+		for {
+			valueLine, err := d.readLine()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return entry, err
+			}
+			valueLine = stripANSI(valueLine)
+			if !strings.HasPrefix(valueLine, multilineIndent+multilineIndent) {
+				d.unreadLine(valueLine)
+				break
+			}
+			lines = append(lines, strings.TrimPrefix(valueLine, multilineIndent+multilineIndent))
+		}
+		entry.Fields = append(entry.Fields, logpy.String(key, strings.Join(lines, "\n")))
+	}
+
+	return entry, nil
+}
+
+// parseFields parses logfmt-style "key=value" pairs, with context fields after a "|"
+// It reports false if s contains anything else
+func parseFields(s string) (fields, contextFields []logpy.Field, ok bool) {
+	inContext := false
+	for {
+		s = strings.TrimLeft(s, " ")
+		if s == "" {
+			return fields, contextFields, true
+		}
+
+		// Context separator
+		if s[0] == '|' && (len(s) == 1 || s[1] == ' ') {
+			if inContext {
+				return nil, nil, false
+			}
+			inContext = true
+			s = s[1:]
+			continue
+		}
+
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 || strings.ContainsAny(s[:eq], " \"") {
+			return nil, nil, false
+		}
+		key := s[:eq]
+		s = s[eq+1:]
+
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			n := quotedLen(s)
+			if n < 0 {
+				return nil, nil, false
+			}
+			unquoted, err := strconv.Unquote(s[:n])
+			if err != nil {
+				return nil, nil, false
+			}
+			value = unquoted
+			s = s[n:]
+			if s != "" && s[0] != ' ' {
+				return nil, nil, false
+			}
+		} else {
+			end := strings.IndexByte(s, ' ')
+			if end < 0 {
+				end = len(s)
+			}
+			if end == 0 {
+				return nil, nil, false
+			}
+			value = s[:end]
+			s = s[end:]
+		}
+
+		if inContext {
+			contextFields = append(contextFields, logpy.String(key, value))
+		} else {
+			fields = append(fields, logpy.String(key, value))
+		}
+	}
+}
+
+// quotedLen returns the length of the double-quoted string at the start of s, or -1
+func quotedLen(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return -1
+}
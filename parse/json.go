@@ -0,0 +1,213 @@
+package parse
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/nhatpy/logpy"
+)
+
+// decodeJSON decodes a JSON entry starting at line, reading more lines for pretty-printed JSON
+func (d *Decoder) decodeJSON(line string) (logpy.Entry, error) {
+	data := line
+	if !json.Valid([]byte(data)) {
+		// Only a line holding just "{" starts a pretty-printed entry; any other
+		// invalid line is a single bad entry, so decoding resumes on the next line
+		if strings.TrimSpace(line) != "{" {
+			return logpy.Entry{}, d.syntaxError("invalid JSON entry")
+		}
+		var err error
+		if data, err = d.readPrettyJSON(line); err != nil {
+			return logpy.Entry{}, err
+		}
+	}
+
+	dec := json.NewDecoder(strings.NewReader(data))
+	dec.UseNumber()
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return logpy.Entry{}, d.syntaxError("JSON entry is not an object")
+	}
+
+	var entry logpy.Entry
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return logpy.Entry{}, d.syntaxError("%v", err)
+		}
+		key := tok.(string)
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return logpy.Entry{}, d.syntaxError("%v", err)
+		}
+
+		switch key {
+		case "timestamp":
+			t, err := d.jsonTime(raw)
+			if err != nil {
+				return logpy.Entry{}, d.syntaxError("invalid timestamp %s", raw)
+			}
+			entry.Time = t
+		case "level":
+			var s string
+			var n json.Number
+			level, ok := logpy.Level(0), false
+			if json.Unmarshal(raw, &s) == nil {
+				level, ok = d.parseLevel(s)
+			} else if json.Unmarshal(raw, &n) == nil {
+				level, ok = d.parseNumericLevel(n)
+			}
+			if !ok {
+				return logpy.Entry{}, d.syntaxError("invalid level %s", raw)
+			}
+			entry.Level = level
+		case "caller":
+			var s string
+			if json.Unmarshal(raw, &s) == nil {
+				entry.Caller, _ = parseCaller(s)
+			}
+		case "message":
+			if err := json.Unmarshal(raw, &entry.Message); err != nil {
+				return logpy.Entry{}, d.syntaxError("invalid message %s", raw)
+			}
+		case "context":
+			fields, err := jsonObjectFields(raw)
+			if err != nil {
+				// Not the context object written by logpy, keep it as a regular field
+				entry.Fields = append(entry.Fields, Field(key, raw))
+				continue
+			}
+			entry.ContextFields = fields
+		default:
+			entry.Fields = append(entry.Fields, Field(key, raw))
+		}
+	}
+	return entry, nil
+}
+
+// readPrettyJSON reads the indented lines of a pretty-printed entry up to its closing "}"
+// A line that is not indented ends the entry early; it is kept for the next Decode
+func (d *Decoder) readPrettyJSON(first string) (string, error) {
+	var b strings.Builder
+	b.WriteString(first)
+	for {
+		next, err := d.readLine()
+		if err == io.EOF {
+			return "", d.syntaxError("unterminated JSON entry")
+		}
+		if err != nil {
+			return "", err
+		}
+		next = stripANSI(next)
+		b.WriteByte('\n')
+		b.WriteString(next)
+		if strings.HasPrefix(next, " ") || strings.HasPrefix(next, "\t") {
+			continue
+		}
+		if strings.TrimSpace(next) == "}" {
+			return b.String(), nil
+		}
+		d.unreadLine(next)
+		return "", d.syntaxError("unterminated JSON entry")
+	}
+}
+
+// jsonTime decodes an RFC 3339 (or configured layout) string or an epoch number
+func (d *Decoder) jsonTime(raw json.RawMessage) (time.Time, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			t, err = time.ParseInLocation(d.timestampFormat, s, d.location)
+		}
+		return t, err
+	}
+	return Time(raw, d.location)
+}
+
+// Time decodes a JSON timestamp: an RFC 3339 string or a Unix time in seconds,
+// milliseconds, microseconds or nanoseconds, guessing the unit from the magnitude
+// Unix times are returned in loc
+func Time(raw json.RawMessage, loc *time.Location) (time.Time, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return time.Parse(time.RFC3339Nano, s)
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err != nil {
+		return time.Time{}, err
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return time.Time{}, err
+	}
+	switch abs := math.Abs(f); {
+	case abs < 1e11:
+		sec, frac := math.Modf(f)
+		return time.Unix(int64(sec), int64(frac*1e9)).In(loc), nil
+	case abs < 1e14:
+		return time.UnixMilli(int64(f)).In(loc), nil
+	case abs < 1e17:
+		return time.UnixMicro(int64(f)).In(loc), nil
+	default:
+		ns, err := n.Int64()
+		if err != nil {
+			ns = int64(f)
+		}
+		return time.Unix(0, ns).In(loc), nil
+	}
+}
+
+// jsonObjectFields converts a JSON object to fields in document order
+func jsonObjectFields(raw json.RawMessage) ([]logpy.Field, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, ErrSyntax
+	}
+
+	var fields []logpy.Field
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		fields = append(fields, Field(tok.(string), value))
+	}
+	return fields, nil
+}
+
+// Field converts a JSON value to the closest typed field
+// Integers become Int64, other numbers Float64; arrays and objects become Any
+func Field(key string, raw json.RawMessage) logpy.Field {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return logpy.String(key, string(raw))
+	}
+
+	switch v := value.(type) {
+	case string:
+		return logpy.String(key, v)
+	case bool:
+		return logpy.Bool(key, v)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return logpy.Int64(key, i)
+		}
+		f, _ := v.Float64()
+		return logpy.Float64(key, f)
+	default:
+		return logpy.Any(key, value)
+	}
+}
@@ -0,0 +1,50 @@
+package parse
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nhatpy/logpy"
+)
+
+func TestDecodeJSONLevel(t *testing.T) {
+	tests := []struct {
+		name     string
+		level    string
+		encoding logpy.LevelEncoding
+		labels   map[logpy.Level]string
+		want     logpy.Level
+		wantErr  bool
+	}{
+		{"upper name", `"WARN"`, "", nil, logpy.WarnLevel, false},
+		{"lower name", `"error"`, "", nil, logpy.ErrorLevel, false},
+		{"level number", `1`, "", nil, logpy.InfoLevel, false},
+		{"severity", `4`, "", nil, logpy.WarnLevel, false},
+		{"notice severity", `5`, "", nil, logpy.InfoLevel, false},
+		{"forced severity", `3`, logpy.LevelSeverity, nil, logpy.ErrorLevel, false},
+		{"forced severity below error", `0`, logpy.LevelSeverity, nil, logpy.ErrorLevel, false},
+		{"forced level number", `6`, logpy.LevelNumber, nil, logpy.InfoLevel, true},
+		{"label", `"W"`, "", map[logpy.Level]string{logpy.WarnLevel: "W"}, logpy.WarnLevel, false},
+		{"name with labels", `"warn"`, "", map[logpy.Level]string{logpy.WarnLevel: "W"}, logpy.WarnLevel, false},
+		{"unknown name", `"LOUD"`, "", nil, logpy.InfoLevel, true},
+		{"out of range", `9`, "", nil, logpy.InfoLevel, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dec := NewDecoder(strings.NewReader(`{"level":` + tt.level + `,"message":"m"}`))
+			if tt.encoding != "" {
+				dec.SetLevelEncoding(tt.encoding)
+			}
+			if tt.labels != nil {
+				dec.SetLevelLabels(tt.labels)
+			}
+			entry, err := dec.Decode()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Decode error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && entry.Level != tt.want {
+				t.Errorf("level = %s, want %s", entry.Level, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+// Package parse reads entries written by logpy's JSON and console formatters
+// back into logpy.Entry values, for post-processing, test assertions and tooling
+//
+// Example:
+//
+//	dec := parse.NewDecoder(file)
+//	for {
+//		entry, err := dec.Decode()
+//		if err == io.EOF {
+//			break
+//		}
+//		...
+//	}
+package parse
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/nhatpy/logpy"
+)
+
+// Format selects how lines are decoded
+type Format string
+
+const (
+	FormatAuto    Format = "auto"    // Detect per entry: lines starting with '{' are JSON
+	FormatJSON    Format = "json"    // JSON lines, including pretty-printed JSON
+	FormatConsole Format = "console" // Console lines, with or without colors
+)
+
+// defaultConsoleTimestampFormat matches the console formatter's default layout
+const defaultConsoleTimestampFormat = "2006-01-02 15:04:05"
+
+// multilineIndent matches the indentation of multi-line console values
+const multilineIndent = "    "
+
+// ErrSyntax is wrapped by errors for lines that are not valid log entries
+var ErrSyntax = errors.New("parse: invalid log entry")
+
+// Decoder reads log entries from an input stream
+type Decoder struct {
+	r               *bufio.Reader
+	format          Format
+	timestampFormat string
+	location        *time.Location
+	levelEncoding   logpy.LevelEncoding
+	levelLabels     map[string]logpy.Level // Level by label, from SetLevelLabels
+	line            int                    // Number of the last line read
+	pending         string                 // Line read ahead but not consumed yet
+	hasPending      bool
+}
+
+// NewDecoder creates a decoder that reads from r and detects the format of each entry
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{
+		r:               bufio.NewReader(r),
+		format:          FormatAuto,
+		timestampFormat: defaultConsoleTimestampFormat,
+		location:        time.Local,
+	}
+}
+
+// SetFormat forces the input format instead of detecting it
+func (d *Decoder) SetFormat(format Format) {
+	d.format = format
+}
+
+// SetTimestampFormat sets the layout of console timestamps (default "2006-01-02 15:04:05")
+// JSON timestamps are also tried with this layout when they are not RFC 3339
+func (d *Decoder) SetTimestampFormat(layout string) {
+	d.timestampFormat = layout
+}
+
+// SetLocation sets the time zone of timestamps that do not carry one (default local time)
+func (d *Decoder) SetLocation(loc *time.Location) {
+	if loc == nil {
+		loc = time.Local
+	}
+	d.location = loc
+}
+
+// SetLevelEncoding sets how numeric JSON levels were written: logpy.LevelNumber or
+// logpy.LevelSeverity. By default numbers 0-3 are level numbers and 4-7 severities
+func (d *Decoder) SetLevelEncoding(enc logpy.LevelEncoding) {
+	d.levelEncoding = enc
+}
+
+// SetLevelLabels sets the level names written by a formatter with LevelLabels,
+// e.g. {logpy.WarnLevel: "W"}; level names keep being accepted
+func (d *Decoder) SetLevelLabels(labels map[logpy.Level]string) {
+	d.levelLabels = make(map[string]logpy.Level, len(labels))
+	for level, label := range labels {
+		d.levelLabels[label] = level
+	}
+}
+
+// Decode returns the next entry, or io.EOF when the input is exhausted
+// Blank lines are skipped; lines that cannot be parsed return an error wrapping
+// ErrSyntax, and decoding can continue with the next entry
+func (d *Decoder) Decode() (logpy.Entry, error) {
+	for {
+		line, err := d.readLine()
+		if err != nil {
+			return logpy.Entry{}, err
+		}
+		line = stripANSI(line)
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		isJSON := strings.HasPrefix(strings.TrimSpace(line), "{")
+		switch {
+		case d.format == FormatJSON || (d.format != FormatConsole && isJSON):
+			return d.decodeJSON(line)
+		default:
+			return d.decodeConsole(line)
+		}
+	}
+}
+
+// All reads every entry from r, stopping at the first error
+func All(r io.Reader) ([]logpy.Entry, error) {
+	dec := NewDecoder(r)
+	var entries []logpy.Entry
+	for {
+		entry, err := dec.Decode()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return entries, err
+		}
+		entries = append(entries, entry)
+	}
+}
+
+// readLine returns the next line without its line ending
+func (d *Decoder) readLine() (string, error) {
+	if d.hasPending {
+		d.hasPending = false
+		return d.pending, nil
+	}
+	line, err := d.r.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	d.line++
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// unreadLine pushes line back so the next readLine returns it
+func (d *Decoder) unreadLine(line string) {
+	d.pending = line
+	d.hasPending = true
+}
+
+// syntaxError reports an unparsable entry at the current line
+func (d *Decoder) syntaxError(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: line %d: %s", ErrSyntax, d.line, fmt.Sprintf(format, args...))
+}
+
+// stripANSI removes ANSI escape sequences such as color codes
+func stripANSI(s string) string {
+	if !strings.Contains(s, "\x1b[") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == 0x1b && i+1 < len(s) && s[i+1] == '[' {
+			// Skip parameter and intermediate bytes up to the final byte (0x40-0x7E)
+			j := i + 2
+			for j < len(s) && (s[j] < 0x40 || s[j] > 0x7e) {
+				j++
+			}
+			i = j
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// parseCaller splits a "file:line" caller
+func parseCaller(s string) (logpy.CallerInfo, bool) {
+	i := strings.LastIndexByte(s, ':')
+	if i <= 0 || i == len(s)-1 {
+		return logpy.CallerInfo{}, false
+	}
+	line := 0
+	for _, c := range s[i+1:] {
+		if c < '0' || c > '9' {
+			return logpy.CallerInfo{}, false
+		}
+		line = line*10 + int(c-'0')
+	}
+	return logpy.CallerInfo{File: s[:i], Line: line}, true
+}
+
+// parseLevel converts a level label or name, including registered levels, rejecting
+// unknown names
+func (d *Decoder) parseLevel(s string) (logpy.Level, bool) {
+	if level, ok := d.levelLabels[s]; ok {
+		return level, true
+	}
+	level, err := logpy.ParseLevelStrict(s)
+	return level, err == nil
+}
+
+// parseNumericLevel converts a level number or RFC 5424 severity according to the
+// level encoding
+func (d *Decoder) parseNumericLevel(n json.Number) (logpy.Level, bool) {
+	if d.levelEncoding != logpy.LevelSeverity {
+		if level, err := logpy.ParseLevelStrict(n.String()); err == nil {
+			return level, true
+		}
+		if d.levelEncoding == logpy.LevelNumber {
+			return logpy.InfoLevel, false
+		}
+	}
+	severity, err := n.Int64()
+	if err != nil {
+		return logpy.InfoLevel, false
+	}
+	return severityLevel(severity)
+}
+
+// severityLevel converts an RFC 5424 severity to the level written with it: a built-in
+// level, a registered level with that severity, or else the next less severe built-in
+// level (5 notice becomes Info, 0-2 emergency to critical become Error)
+func severityLevel(severity int64) (logpy.Level, bool) {
+	if severity < 0 || severity > 7 {
+		return logpy.InfoLevel, false
+	}
+	levels := logpy.Levels()
+	for _, builtin := range []bool{true, false} {
+		for _, level := range levels {
+			if isBuiltin(level) == builtin && int64(level.Severity()) == severity {
+				return level, true
+			}
+		}
+	}
+	if severity < int64(logpy.ErrorLevel.Severity()) {
+		return logpy.ErrorLevel, true
+	}
+	return logpy.InfoLevel, true
+}
+
+// isBuiltin reports whether level is one of logpy's predefined levels
+func isBuiltin(level logpy.Level) bool {
+	switch level {
+	case logpy.DebugLevel, logpy.InfoLevel, logpy.WarnLevel, logpy.ErrorLevel:
+		return true
+	}
+	return false
+}
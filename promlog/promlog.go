@@ -0,0 +1,78 @@
+// Package promlog exposes logpy's logging counters as Prometheus metrics
+//
+// Register the collector and serve it with promhttp:
+//
+//	prometheus.MustRegister(promlog.NewCollector())
+//	http.Handle("/metrics", promhttp.Handler())
+package promlog
+
+import (
+	"github.com/nhatpy/logpy"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector is a prometheus.Collector that reports logpy.ReadMetrics
+type Collector struct {
+	entries      *prometheus.Desc
+	writeErrors  *prometheus.Desc
+	dropped      *prometheus.Desc
+	bytesWritten *prometheus.Desc
+	rotations    *prometheus.Desc
+}
+
+// NewCollector creates a collector with metrics named logpy_*
+func NewCollector() *Collector {
+	return NewCollectorWithNamespace("logpy")
+}
+
+// NewCollectorWithNamespace creates a collector whose metric names start with namespace
+func NewCollectorWithNamespace(namespace string) *Collector {
+	return &Collector{
+		entries: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "entries_total"),
+			"Number of log entries, by level.",
+			[]string{"level"}, nil,
+		),
+		writeErrors: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "write_errors_total"),
+			"Number of log entries a handler failed to write.",
+			nil, nil,
+		),
+		dropped: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "dropped_entries_total"),
+			"Number of log entries dropped by asynchronous handlers.",
+			nil, nil,
+		),
+		bytesWritten: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "bytes_written_total"),
+			"Number of bytes written by log handlers.",
+			nil, nil,
+		),
+		rotations: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "rotations_total"),
+			"Number of log file rotations.",
+			nil, nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.entries
+	ch <- c.writeErrors
+	ch <- c.dropped
+	ch <- c.bytesWritten
+	ch <- c.rotations
+}
+
+// Collect implements prometheus.Collector
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	snap := logpy.ReadMetrics()
+	for level, n := range snap.Entries {
+		ch <- prometheus.MustNewConstMetric(c.entries, prometheus.CounterValue, float64(n), level.String())
+	}
+	ch <- prometheus.MustNewConstMetric(c.writeErrors, prometheus.CounterValue, float64(snap.WriteErrors))
+	ch <- prometheus.MustNewConstMetric(c.dropped, prometheus.CounterValue, float64(snap.Dropped))
+	ch <- prometheus.MustNewConstMetric(c.bytesWritten, prometheus.CounterValue, float64(snap.BytesWritten))
+	ch <- prometheus.MustNewConstMetric(c.rotations, prometheus.CounterValue, float64(snap.Rotations))
+}
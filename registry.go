@@ -0,0 +1,79 @@
+package logpy
+
+import (
+	"fmt"
+	"sync"
+)
+
+// FormatterFactory creates a formatter from the config of the logger being built
+type FormatterFactory func(cfg Config) Formatter
+
+// OutputFactory creates the handler of a registered destination from the config of the logger being built
+type OutputFactory func(cfg Config) (Handler, error)
+
+var registry = struct {
+	mu         sync.RWMutex
+	formatters map[FormatType]FormatterFactory
+	handlers   map[OutputType]OutputFactory
+}{
+	formatters: make(map[FormatType]FormatterFactory),
+	handlers:   make(map[OutputType]OutputFactory),
+}
+
+// RegisterFormatter makes a formatter available by name, so a config can select it
+// with Format, ConsoleFormat, FileFormat or a section's Format (e.g. Format: "gelf")
+// It is meant to be called from init; it panics if factory is nil or name is
+// empty, built in or already registered
+func RegisterFormatter(name string, factory FormatterFactory) {
+	format := FormatType(name)
+	if factory == nil || name == "" || builtinFormat(format) {
+		panic(fmt.Sprintf("logpy: cannot register formatter %q", name))
+	}
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if _, dup := registry.formatters[format]; dup {
+		panic(fmt.Sprintf("logpy: formatter %q registered twice", name))
+	}
+	registry.formatters[format] = factory
+}
+
+// RegisterHandler makes a destination available by name, so a config can select it
+// with Output (e.g. Output: "mycorp-audit"); the factory receives the whole config
+// It is meant to be called from init; it panics if factory is nil or name is
+// empty, built in or already registered
+func RegisterHandler(name string, factory OutputFactory) {
+	output := OutputType(name)
+	if factory == nil || name == "" || oneOf(output, OutputStdout, OutputStderr, OutputFile) {
+		panic(fmt.Sprintf("logpy: cannot register handler %q", name))
+	}
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if _, dup := registry.handlers[output]; dup {
+		panic(fmt.Sprintf("logpy: handler %q registered twice", name))
+	}
+	registry.handlers[output] = factory
+}
+
+// registeredFormatter creates the registered formatter named format
+func (c Config) registeredFormatter(format FormatType) (Formatter, bool) {
+	registry.mu.RLock()
+	factory, ok := registry.formatters[format]
+	registry.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	return factory(c), true
+}
+
+// registeredHandler returns the factory of the registered handler named output
+func registeredHandler(output OutputType) (OutputFactory, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	factory, ok := registry.handlers[output]
+	return factory, ok
+}
+
+// builtinFormat reports whether format is one of the formats logpy implements
+func builtinFormat(format FormatType) bool {
+	return oneOf(format, FormatJSON, FormatConsole, FormatMsgpack, FormatCBOR)
+}
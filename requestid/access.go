@@ -0,0 +1,89 @@
+package requestid
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/nhatpy/logpy"
+)
+
+// AccessLog logs one entry per request with the access field keys read by
+// logpy.ApacheFormatter and logpy.W3CFormatter: client_ip, user, method, path, query,
+// host, proto, status, bytes, request_bytes, duration, referer and user_agent
+// Wrap it in Middleware to add the request ID and trace. Entries are logged at Info,
+// or Error for 5xx responses. Give logger an ApacheHandler or W3CHandler, alone or
+// in a MultiHandler with a structured handler, to write access log lines
+func AccessLog(logger *logpy.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+
+		log := Logger(r.Context(), logger)
+		var event *logpy.Event
+		if rec.status >= 500 {
+			event = log.Error()
+		} else {
+			event = log.Info()
+		}
+		clientIP, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			clientIP = r.RemoteAddr
+		}
+		event = event.Str(logpy.AccessClientIP, clientIP)
+		if user, _, ok := r.BasicAuth(); ok && user != "" {
+			event = event.Str(logpy.AccessUser, user)
+		}
+		event = event.Str(logpy.AccessMethod, r.Method).Str(logpy.AccessPath, r.URL.Path)
+		if r.URL.RawQuery != "" {
+			event = event.Str(logpy.AccessQuery, r.URL.RawQuery)
+		}
+		event = event.Str(logpy.AccessHost, r.Host).Str(logpy.AccessProto, r.Proto).
+			Int(logpy.AccessStatus, rec.status).Int64(logpy.AccessBytes, rec.bytes)
+		if r.ContentLength > 0 {
+			event = event.Int64(logpy.AccessRequestBytes, r.ContentLength)
+		}
+		event = event.Dur(logpy.AccessDuration, time.Since(start))
+		if referer := r.Referer(); referer != "" {
+			event = event.Str(logpy.AccessReferer, referer)
+		}
+		if userAgent := r.UserAgent(); userAgent != "" {
+			event = event.Str(logpy.AccessUserAgent, userAgent)
+		}
+		event.Msg("request")
+	})
+}
+
+// statusRecorder captures the status code and body size of a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+// WriteHeader implements http.ResponseWriter
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write implements http.ResponseWriter
+func (r *statusRecorder) Write(p []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(p)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, e.g. to flush
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,251 @@
+// Package requestid generates request IDs, carries them in a context and adds them
+// to log entries, so every entry of a request can be correlated.
+//
+// Example:
+//
+//	mux := http.NewServeMux()
+//	mux.HandleFunc("/orders", func(w http.ResponseWriter, r *http.Request) {
+//		log := requestid.Logger(r.Context(), logger)
+//		log.Info().Msg("listing orders") // request_id=0190c3e4-...
+//	})
+//	http.ListenAndServe(":8080", requestid.Middleware(mux))
+//
+// On Cloud Run and App Engine the middleware also reads the X-Cloud-Trace-Context or
+// traceparent header, and Logger adds the logging.googleapis.com/trace fields so
+// request logs nest under the request trace in Cloud Logging.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nhatpy/logpy"
+)
+
+// Header is the HTTP header that carries the request ID
+const Header = "X-Request-ID"
+
+// FieldKey is the key of the request ID field
+const FieldKey = "request_id"
+
+// maxIncomingLen limits the length of IDs accepted from clients
+const maxIncomingLen = 128
+
+// Trace context headers read by Middleware
+const (
+	CloudTraceHeader  = "X-Cloud-Trace-Context" // TRACE_ID/SPAN_ID;o=1, set by Google front ends
+	TraceparentHeader = "traceparent"           // W3C Trace Context
+)
+
+// Keys of the trace fields recognized by Cloud Logging
+const (
+	TraceKey        = "logging.googleapis.com/trace"
+	SpanIDKey       = "logging.googleapis.com/spanId"
+	TraceSampledKey = "logging.googleapis.com/trace_sampled"
+)
+
+// ProjectID is the Google Cloud project in the trace field, "projects/ID/traces/TRACE_ID"
+// It defaults to GOOGLE_CLOUD_PROJECT (set on App Engine; set it on Cloud Run). When
+// empty the field holds the bare trace ID and entries are not nested under the trace
+var ProjectID = os.Getenv("GOOGLE_CLOUD_PROJECT")
+
+// contextKey is the context key for the request ID
+type contextKey struct{}
+
+// traceContextKey is the context key for the trace
+type traceContextKey struct{}
+
+// Trace identifies the trace and span of a request
+type Trace struct {
+	TraceID string // 32 lowercase hex characters
+	SpanID  string // 16 lowercase hex characters, empty if unknown
+	Sampled bool
+}
+
+// New returns a new UUIDv7: time-ordered, so IDs sort by creation time
+func New() string {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], uint64(time.Now().UnixMilli())<<16)
+	rand.Read(b[6:])
+	b[6] = 0x70 | b[6]&0x0f // Version 7
+	b[8] = 0x80 | b[8]&0x3f // RFC 9562 variant
+
+	var s [36]byte
+	hex.Encode(s[0:8], b[0:4])
+	s[8] = '-'
+	hex.Encode(s[9:13], b[4:6])
+	s[13] = '-'
+	hex.Encode(s[14:18], b[6:8])
+	s[18] = '-'
+	hex.Encode(s[19:23], b[8:10])
+	s[23] = '-'
+	hex.Encode(s[24:], b[10:])
+	return string(s[:])
+}
+
+// NewContext returns a copy of ctx carrying id
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID stored in ctx, or ""
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Field returns the request ID in ctx as a "request_id" field
+func Field(ctx context.Context) logpy.Field {
+	return logpy.String(FieldKey, FromContext(ctx))
+}
+
+// NewTraceContext returns a copy of ctx carrying trace
+func NewTraceContext(ctx context.Context, trace Trace) context.Context {
+	return context.WithValue(ctx, traceContextKey{}, trace)
+}
+
+// TraceFromContext returns the trace stored in ctx
+func TraceFromContext(ctx context.Context) (Trace, bool) {
+	trace, ok := ctx.Value(traceContextKey{}).(Trace)
+	return trace, ok
+}
+
+// TraceFields returns the trace in ctx as Cloud Logging trace, span and sampled fields,
+// or nil when ctx has no trace
+func TraceFields(ctx context.Context) []logpy.Field {
+	trace, ok := TraceFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	name := trace.TraceID
+	if ProjectID != "" {
+		name = "projects/" + ProjectID + "/traces/" + trace.TraceID
+	}
+	fields := []logpy.Field{logpy.String(TraceKey, name)}
+	if trace.SpanID != "" {
+		fields = append(fields, logpy.String(SpanIDKey, trace.SpanID))
+	}
+	return append(fields, logpy.Bool(TraceSampledKey, trace.Sampled))
+}
+
+// Logger returns a child of logger with the request ID and trace in ctx as context fields
+// logger is returned unchanged when ctx has neither
+func Logger(ctx context.Context, logger *logpy.Logger) *logpy.Logger {
+	var fields []logpy.Field
+	if FromContext(ctx) != "" {
+		fields = append(fields, Field(ctx))
+	}
+	fields = append(fields, TraceFields(ctx)...)
+	if len(fields) == 0 {
+		return logger
+	}
+	return logger.With(fields...)
+}
+
+// Extractor returns a context extractor adding the request ID and trace in ctx,
+// for Config.ContextExtractors and Logger.Ctx
+func Extractor() logpy.ContextExtractor {
+	return func(ctx context.Context) []logpy.Field {
+		var fields []logpy.Field
+		if FromContext(ctx) != "" {
+			fields = append(fields, Field(ctx))
+		}
+		return append(fields, TraceFields(ctx)...)
+	}
+}
+
+// Middleware stores a request ID in the request context and echoes it in the
+// X-Request-ID response header. A valid incoming X-Request-ID is reused so IDs
+// propagate across services; otherwise a new one is generated. A trace from the
+// traceparent or X-Cloud-Trace-Context header is stored as well
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(Header)
+		if !valid(id) {
+			id = New()
+		}
+		w.Header().Set(Header, id)
+		ctx := NewContext(r.Context(), id)
+		if trace, ok := ParseTrace(r.Header); ok {
+			ctx = NewTraceContext(ctx, trace)
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// ParseTrace reads the trace from the traceparent header, or else the
+// X-Cloud-Trace-Context header; malformed headers are ignored
+func ParseTrace(h http.Header) (Trace, bool) {
+	if trace, ok := parseTraceparent(h.Get(TraceparentHeader)); ok {
+		return trace, true
+	}
+	return parseCloudTrace(h.Get(CloudTraceHeader))
+}
+
+// parseTraceparent parses "00-TRACE_ID-SPAN_ID-FLAGS"
+func parseTraceparent(s string) (Trace, bool) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[3]) != 2 {
+		return Trace{}, false
+	}
+	traceID, spanID := strings.ToLower(parts[1]), strings.ToLower(parts[2])
+	if !hexID(traceID, 32) || !hexID(spanID, 16) {
+		return Trace{}, false
+	}
+	flags, err := strconv.ParseUint(parts[3], 16, 8)
+	if err != nil {
+		return Trace{}, false
+	}
+	return Trace{TraceID: traceID, SpanID: spanID, Sampled: flags&1 == 1}, true
+}
+
+// parseCloudTrace parses "TRACE_ID/SPAN_ID;o=OPTIONS", where SPAN_ID is decimal
+// and both SPAN_ID and OPTIONS are optional
+func parseCloudTrace(s string) (Trace, bool) {
+	s, options, _ := strings.Cut(strings.TrimSpace(s), ";")
+	traceID, span, _ := strings.Cut(s, "/")
+	traceID = strings.ToLower(traceID)
+	if !hexID(traceID, 32) {
+		return Trace{}, false
+	}
+	trace := Trace{TraceID: traceID, Sampled: strings.TrimSpace(options) == "o=1"}
+	if n, err := strconv.ParseUint(span, 10, 64); err == nil && n != 0 {
+		trace.SpanID = fmt.Sprintf("%016x", n)
+	}
+	return trace, true
+}
+
+// hexID reports whether s is a non-zero lowercase hex ID of length n
+func hexID(s string, n int) bool {
+	if len(s) != n || strings.Trim(s, "0") == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// valid reports whether an incoming ID is safe to reuse and log
+func valid(id string) bool {
+	if id == "" || len(id) > maxIncomingLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		// Printable ASCII without spaces or quotes
+		if c := id[i]; c <= ' ' || c > '~' || c == '"' {
+			return false
+		}
+	}
+	return true
+}
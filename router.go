@@ -0,0 +1,98 @@
+package logpy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+)
+
+// LevelRouterHandler sends entries below a threshold level to one handler and
+// entries at or above it to another, e.g. Debug/Info to stdout and Warn/Error to stderr
+type LevelRouterHandler struct {
+	threshold Level
+	low       Handler
+	high      Handler
+}
+
+// NewLevelRouterHandler creates a handler that routes entries below threshold to low
+// and all other entries to high. Nest routers to send more levels to separate outputs
+func NewLevelRouterHandler(threshold Level, low, high Handler) *LevelRouterHandler {
+	return &LevelRouterHandler{
+		threshold: threshold,
+		low:       low,
+		high:      high,
+	}
+}
+
+// route returns the handler for level
+func (h *LevelRouterHandler) route(level Level) Handler {
+	if !level.AtLeast(h.threshold) {
+		return h.low
+	}
+	return h.high
+}
+
+// handlers returns the distinct handlers behind the router
+func (h *LevelRouterHandler) handlers() []Handler {
+	if h.low == h.high {
+		return []Handler{h.low}
+	}
+	return []Handler{h.low, h.high}
+}
+
+// Enabled implements the Handler interface
+func (h *LevelRouterHandler) Enabled(level Level) bool {
+	return h.route(level).Enabled(level)
+}
+
+// Handle implements the Handler interface
+func (h *LevelRouterHandler) Handle(entry Entry) error {
+	return h.route(entry.Level).Handle(entry)
+}
+
+// WithFields implements the Handler interface
+func (h *LevelRouterHandler) WithFields(fields []Field) Handler {
+	return NewLevelRouterHandler(h.threshold, h.low.WithFields(fields), h.high.WithFields(fields))
+}
+
+// Flush flushes both routes
+func (h *LevelRouterHandler) Flush() error {
+	var errs []error
+	for _, handler := range h.handlers() {
+		if f, ok := handler.(Flusher); ok {
+			errs = append(errs, f.Flush())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Drain implements the Drainer interface for asynchronous routes
+func (h *LevelRouterHandler) Drain(ctx context.Context) (int, error) {
+	var dropped int
+	var errs []error
+	for _, handler := range h.handlers() {
+		if d, ok := handler.(Drainer); ok {
+			n, err := d.Drain(ctx)
+			dropped += n
+			errs = append(errs, err)
+		}
+	}
+	return dropped, errors.Join(errs...)
+}
+
+// Close closes both routes
+func (h *LevelRouterHandler) Close() error {
+	var errs []error
+	for _, handler := range h.handlers() {
+		if c, ok := handler.(io.Closer); ok {
+			errs = append(errs, c.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// createSplitHandler sends Debug/Info to stdout and Warn/Error to stderr
+func createSplitHandler(cfg Config) Handler {
+	return NewLevelRouterHandler(WarnLevel, createStreamHandler(cfg, os.Stdout), createStreamHandler(cfg, os.Stderr))
+}
@@ -0,0 +1,119 @@
+package logpy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+)
+
+// errValueCycle is reported for Any values that reference themselves
+var errValueCycle = errors.New("value contains a cycle")
+
+// badValue returns the placeholder written instead of a value that cannot be encoded
+// and reports err on stderr, so the rest of the entry is still written
+func badValue(key string, err error) string {
+	fmt.Fprintf(os.Stderr, "error encoding log field %q: %v\n", key, err)
+	return fmt.Sprintf("!BADVALUE(%v)", err)
+}
+
+// safeError returns err.Error(), recovering from a panic in the method
+func safeError(err error) string {
+	return safeText(err, err.Error)
+}
+
+// safeString returns v.String(), recovering from a panic in the method
+func safeString(v fmt.Stringer) string {
+	return safeText(v, v.String)
+}
+
+// safeText calls the Error or String method fn of v. Like fmt and zap, a typed nil
+// pointer whose method dereferences its receiver gives "<nil>" instead of a panic
+func safeText(v interface{}, fn func() string) (s string) {
+	defer func() {
+		if r := recover(); r != nil {
+			if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+				s = "<nil>"
+				return
+			}
+			s = fmt.Sprintf("!PANIC(%v)", r)
+		}
+	}()
+	return fn()
+}
+
+// safeJSONValue returns value, or a placeholder when it cannot be encoded as JSON:
+// channels, funcs, NaN, cycles, or a MarshalJSON method that fails or panics
+func safeJSONValue(key string, value interface{}) interface{} {
+	if _, err := marshalJSON(value, "", false); err != nil {
+		return badValue(key, err)
+	}
+	return value
+}
+
+// checkCycles reports whether value references itself through pointers, maps or slices
+// Values with a String or Error method are not walked, since they format themselves
+func checkCycles(value interface{}) error {
+	return walkCycles(reflect.ValueOf(value), make(map[cycleVisit]bool))
+}
+
+// cycleVisit identifies a pointer, map or slice on the current path
+type cycleVisit struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// walkCycles walks v depth-first, tracking the references on the current path
+func walkCycles(v reflect.Value, path map[cycleVisit]bool) error {
+	if !v.IsValid() {
+		return nil
+	}
+	if v.CanInterface() {
+		switch v.Interface().(type) {
+		case fmt.Stringer, error:
+			return nil
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		visit := cycleVisit{ptr: v.Pointer(), typ: v.Type()}
+		if v.Kind() == reflect.Slice {
+			visit.len = v.Len()
+		}
+		if path[visit] {
+			return errValueCycle
+		}
+		path[visit] = true
+		defer delete(path, visit)
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return walkCycles(v.Elem(), path)
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if err := walkCycles(iter.Value(), path); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := walkCycles(v.Index(i), path); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if err := walkCycles(v.Field(i), path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,253 @@
+// Package search scans log directories, including gzipped rotated backups, and
+// returns the parsed entries matching a filter: a programmatic grep for support
+// tooling and admin endpoints. JSON and console files are both read.
+//
+// Example:
+//
+//	entries, err := search.Dir(ctx, "logs", search.Filter{
+//		Since:    time.Now().Add(-24 * time.Hour),
+//		MinLevel: logpy.ErrorLevel.Ptr(),
+//		Fields:   map[string]string{"user_id": "42"},
+//		Limit:    100,
+//	})
+package search
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nhatpy/logpy"
+	"github.com/nhatpy/logpy/parse"
+)
+
+// Filter selects entries; zero values match everything
+type Filter struct {
+	Since    time.Time         // Entries at or after this time
+	Until    time.Time         // Entries before this time
+	MinLevel *logpy.Level      // Entries at or above this level, e.g. logpy.WarnLevel.Ptr()
+	Message  string            // Substring of the message (case-sensitive)
+	Fields   map[string]string // Field values compared as text, event or context fields
+	Limit    int               // Maximum number of entries, the oldest ones (0 for all)
+	Reverse  bool              // Newest entries first; with Limit, the newest ones are kept
+
+	// Pattern is a glob matched against file names (default: names containing ".log",
+	// such as app.log, app.log.1 and app-2024-01-02T15-04-05.000.log.gz)
+	Pattern string
+	// TimestampFormat is the layout of console timestamps (default "2006-01-02 15:04:05")
+	TimestampFormat string
+	// OnSkip receives the number of lines of a file that are not log entries, such as
+	// panic traces; when nil the count is printed to stderr
+	OnSkip func(path string, skipped int)
+}
+
+// Dir searches the log files under dir and its subdirectories
+// Symbolic links, such as latest.log, are skipped so files are not read twice
+func Dir(ctx context.Context, dir string, filter Filter) ([]logpy.Entry, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() && filter.matchName(d.Name()) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return Files(ctx, paths, filter)
+}
+
+// Files searches the given log files; names ending in .gz are decompressed
+// Files last modified before filter.Since are skipped without being read
+func Files(ctx context.Context, paths []string, filter Filter) ([]logpy.Entry, error) {
+	var entries []logpy.Entry
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if !filter.Since.IsZero() {
+			if info, err := os.Stat(path); err == nil && info.ModTime().Before(filter.Since) {
+				continue
+			}
+		}
+		found, err := filter.searchFile(ctx, path)
+		if err != nil {
+			return nil, fmt.Errorf("search: %s: %w", path, err)
+		}
+		entries = append(entries, found...)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if filter.Reverse {
+			return entries[i].Time.After(entries[j].Time)
+		}
+		return entries[i].Time.Before(entries[j].Time)
+	})
+	if filter.Limit > 0 && len(entries) > filter.Limit {
+		entries = entries[:filter.Limit]
+	}
+	return entries, nil
+}
+
+// Reader searches the entries read from r; skipped lines are reported for the path "input"
+func Reader(r io.Reader, filter Filter) ([]logpy.Entry, error) {
+	return filter.read(r, "input")
+}
+
+// read searches the entries read from r and reports the lines skipped in path
+func (f Filter) read(r io.Reader, path string) ([]logpy.Entry, error) {
+	dec := parse.NewDecoder(r)
+	if f.TimestampFormat != "" {
+		dec.SetTimestampFormat(f.TimestampFormat)
+	}
+	var entries []logpy.Entry
+	skipped := 0
+	defer func() {
+		if skipped > 0 {
+			f.reportSkipped(path, skipped)
+		}
+	}()
+	for {
+		entry, err := dec.Decode()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if errors.Is(err, parse.ErrSyntax) {
+			skipped++ // Not a log entry, e.g. a panic trace
+			continue
+		}
+		if err != nil {
+			return entries, err
+		}
+		if f.Match(entry) {
+			entries = append(entries, entry)
+		}
+	}
+}
+
+// reportSkipped passes the number of skipped lines to OnSkip, or prints it to stderr
+func (f Filter) reportSkipped(path string, skipped int) {
+	if f.OnSkip != nil {
+		f.OnSkip(path, skipped)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "search: %s: skipped %d lines that are not log entries\n", path, skipped)
+}
+
+// Match reports whether entry passes the filter, ignoring Limit and the file options
+func (f Filter) Match(entry logpy.Entry) bool {
+	if !f.Since.IsZero() && entry.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !entry.Time.Before(f.Until) {
+		return false
+	}
+	if f.MinLevel != nil && !entry.Level.AtLeast(*f.MinLevel) {
+		return false
+	}
+	if f.Message != "" && !strings.Contains(entry.Message, f.Message) {
+		return false
+	}
+	for key, value := range f.Fields {
+		if !hasField(entry, key, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchName reports whether a file name is searched
+func (f Filter) matchName(name string) bool {
+	if f.Pattern == "" {
+		return strings.Contains(name, ".log")
+	}
+	ok, _ := filepath.Match(f.Pattern, name)
+	return ok
+}
+
+// searchFile searches one file, decompressing gzip files
+func (f Filter) searchFile(ctx context.Context, path string) ([]logpy.Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var r io.Reader = bufio.NewReader(file)
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+	return f.read(&ctxReader{ctx: ctx, r: r}, path)
+}
+
+// ctxReader stops reading a large file when the context is done
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+// Read implements io.Reader
+func (r *ctxReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+// hasField reports whether entry has an event or context field key with the text value
+// Fields inside groups are named by their dotted key, e.g. "db.host"
+func hasField(entry logpy.Entry, key, value string) bool {
+	for _, fields := range [][]logpy.Field{entry.Fields, entry.ContextFields} {
+		if fieldMatches(fields, key, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldMatches looks for key in fields, descending into decoded JSON objects for dotted keys
+func fieldMatches(fields []logpy.Field, key, value string) bool {
+	for _, field := range fields {
+		if field.Key == key && fmt.Sprint(field.Value) == value {
+			return true
+		}
+		if rest, ok := strings.CutPrefix(key, field.Key+"."); ok {
+			if m, ok := field.Value.(map[string]interface{}); ok && objectMatches(m, rest, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// objectMatches looks for a dotted key in a decoded JSON object
+func objectMatches(m map[string]interface{}, key, value string) bool {
+	if v, ok := m[key]; ok && fmt.Sprint(v) == value {
+		return true
+	}
+	for k, v := range m {
+		if rest, ok := strings.CutPrefix(key, k+"."); ok {
+			if nested, ok := v.(map[string]interface{}); ok && objectMatches(nested, rest, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+package logpy
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ConsoleConfig is the console section of a Config
+type ConsoleConfig struct {
+	Level  *Level     // nil uses Config.Level
+	Format FormatType // Default console
+	Color  ColorMode  // Default auto: color only on a terminal
+	Stderr bool       // Write to stderr instead of stdout
+}
+
+// FileConfig is the file section of a Config
+// Rotation limits not set here (MaxTotalSize, FilenameTemplate, ...) come from the flat fields
+type FileConfig struct {
+	Path       string       // Directory or file path, as Config.OutputPath (default ./logs; app.log in it for size rotation)
+	Level      *Level       // nil uses Config.Level
+	Format     FormatType   // Default: console for daily/hourly rotation, JSON for size rotation
+	Rotation   RotationMode // Default daily
+	MaxSize    int          // MB before rotation (0 uses Config.MaxSize)
+	MaxBackups int          // Old files kept with size rotation (0 uses Config.MaxBackups)
+	MaxAge     int          // Days to keep old files (0 uses Config.MaxAge)
+	Compress   bool         // Gzip rotated files
+}
+
+// RemoteConfig is the remote section of a Config: entries are sent in the background
+// over TCP, UDP or a Unix socket, e.g. to a log collector
+type RemoteConfig struct {
+	Network    string      // "tcp", "udp", "unix", ...
+	Address    string      // e.g. "collector:5170"
+	TLS        *TLSOptions // Optional, for TCP: CA bundle, client certificate, ...
+	Level      *Level      // nil uses Config.Level
+	Format     FormatType  // Default JSON
+	BufferSize int         // Entries queued while the collector is slow (default 1024)
+}
+
+// hasSections reports whether any section is set, which replaces the flat
+// Output, MultiOutput, Format and color settings
+func (c Config) hasSections() bool {
+	return c.Console != nil || c.File != nil || c.Remote != nil
+}
+
+// createSectionHandler creates one handler per section, combined when there are several
+func createSectionHandler(cfg Config) (Handler, error) {
+	var handlers []Handler
+	if sec := cfg.Console; sec != nil {
+		handlers = append(handlers, createConsoleSection(cfg, *sec))
+	}
+	if sec := cfg.File; sec != nil {
+		handler, err := createFileSection(cfg, *sec)
+		if err != nil {
+			return nil, err
+		}
+		handlers = append(handlers, handler)
+	}
+	if sec := cfg.Remote; sec != nil {
+		handler, err := createRemoteSection(cfg, *sec)
+		if err != nil {
+			return nil, err
+		}
+		handlers = append(handlers, handler)
+	}
+	if len(handlers) == 1 {
+		return handlers[0], nil
+	}
+	return NewMultiHandler(handlers...), nil
+}
+
+// sectionLevel returns the level of a section, falling back to Level
+func (c Config) sectionLevel(level *Level) Level {
+	if level != nil {
+		return *level
+	}
+	return c.Level
+}
+
+// createConsoleSection creates the console handler of a sectioned config
+func createConsoleSection(cfg Config, sec ConsoleConfig) Handler {
+	legCfg := cfg
+	legCfg.Level = cfg.sectionLevel(sec.Level)
+	legCfg.Format = sec.Format
+	if legCfg.Format == "" {
+		legCfg.Format = FormatConsole
+	}
+	legCfg.ColorMode = sec.Color
+	if legCfg.ColorMode == "" {
+		legCfg.ColorMode = ColorAuto
+	}
+	f := os.Stdout
+	if sec.Stderr {
+		f = os.Stderr
+	}
+	return createStreamHandler(legCfg, f)
+}
+
+// createFileSection creates the file handler of a sectioned config
+func createFileSection(cfg Config, sec FileConfig) (Handler, error) {
+	legCfg := cfg
+	legCfg.Output = OutputFile
+	legCfg.OutputPath = sec.Path
+	if legCfg.OutputPath == "" {
+		legCfg.OutputPath = "./logs"
+	}
+	legCfg.RotationMode = sec.Rotation
+	if legCfg.RotationMode == "" {
+		legCfg.RotationMode = RotationDaily
+	}
+	// Size-based rotation needs a file name; a directory gets app.log
+	if legCfg.RotationMode == RotationSize && !strings.HasSuffix(legCfg.OutputPath, ".log") {
+		legCfg.OutputPath = filepath.Join(legCfg.OutputPath, "app.log")
+	}
+	if sec.MaxSize > 0 {
+		legCfg.MaxSize = sec.MaxSize
+	}
+	if sec.MaxBackups > 0 {
+		legCfg.MaxBackups = sec.MaxBackups
+	}
+	if sec.MaxAge > 0 {
+		legCfg.MaxAge = sec.MaxAge
+	}
+	legCfg.Compress = sec.Compress
+	// The file leg of MultiOutput takes its own level and format and is never colored
+	legCfg.MultiOutput = true
+	legCfg.FileLevel = sec.Level
+	legCfg.FileFormat = sec.Format
+	legCfg.Format = sec.Format
+
+	if legCfg.SplitLevelFiles {
+		return createLevelFilesHandler(legCfg)
+	}
+	return createFileHandler(legCfg)
+}
+
+// createRemoteSection creates the network handler of a sectioned config
+// The connection is closed with the handler, by Logger.Close or Shutdown
+func createRemoteSection(cfg Config, sec RemoteConfig) (Handler, error) {
+	format := sec.Format
+	if format == "" {
+		format = FormatJSON
+	}
+	var tlsConfig *tls.Config
+	if sec.TLS != nil {
+		var err error
+		if tlsConfig, err = sec.TLS.Config(); err != nil {
+			return nil, fmt.Errorf("logpy: remote TLS: %w", err)
+		}
+	}
+	writer := NewNetWriter(sec.Network, sec.Address, tlsConfig)
+	handler := newJSONHandler(writer, cfg.sectionLevel(sec.Level), cfg.formatter(format, false))
+	return NewAsyncHandler(&closingHandler{Handler: handler, closer: writer}, sec.BufferSize), nil
+}
+
+// closingHandler closes a writer created for its handler once the handler is closed
+type closingHandler struct {
+	Handler
+	closer io.Closer
+}
+
+// Flush flushes the wrapped handler
+func (h *closingHandler) Flush() error {
+	if f, ok := h.Handler.(Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// Close implements io.Closer, closing the handler and then its writer
+func (h *closingHandler) Close() error {
+	return errors.Join(closeHandler(h.Handler), h.closer.Close())
+}
@@ -0,0 +1,350 @@
+// Package sqlitelog stores log entries in an embedded SQLite database and queries them
+// by time, level, message and field values, giving small self-hosted apps searchable
+// logs without external infrastructure. It uses a pure Go driver, so cgo is not needed.
+//
+// Example:
+//
+//	store, err := sqlitelog.Open("logs/app.db", logpy.InfoLevel, "user_id", "order_id")
+//	if err != nil {
+//		return err
+//	}
+//	defer store.Close()
+//	logger := logpy.New(logpy.NewMultiHandler(logpy.NewConsoleHandler(logpy.InfoLevel, true), store))
+//
+//	entries, err := store.Query(sqlitelog.Filter{
+//		Since:    time.Now().Add(-time.Hour),
+//		MinLevel: logpy.WarnLevel.Ptr(),
+//		Fields:   map[string]string{"user_id": "42"},
+//	})
+package sqlitelog
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nhatpy/logpy"
+	"github.com/nhatpy/logpy/parse"
+	_ "modernc.org/sqlite" // Registers the "sqlite" driver
+)
+
+// schema creates the tables and indexes; entry_fields holds the values of indexed fields
+const schema = `
+CREATE TABLE IF NOT EXISTS entries (
+	id      INTEGER PRIMARY KEY,
+	time    INTEGER NOT NULL,
+	level   INTEGER NOT NULL,
+	message TEXT NOT NULL,
+	entry   TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS entries_time ON entries(time);
+CREATE INDEX IF NOT EXISTS entries_level_time ON entries(level, time);
+CREATE TABLE IF NOT EXISTS entry_fields (
+	entry_id INTEGER NOT NULL,
+	key      TEXT NOT NULL,
+	value    TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS entry_fields_key_value ON entry_fields(key, value, entry_id);
+CREATE INDEX IF NOT EXISTS entry_fields_entry ON entry_fields(entry_id);
+`
+
+// ErrClosed is returned when writing to or querying a closed store
+var ErrClosed = errors.New("sqlitelog: store is closed")
+
+// Store is a handler writing entries to SQLite
+// Each entry is stored as a JSON line next to indexed time, level and message columns;
+// the values of the indexed fields are stored in a separate indexed table
+type Store struct {
+	db        *sql.DB
+	ownsDB    bool
+	level     logpy.Level
+	indexed   map[string]bool
+	formatter *logpy.JSONFormatter
+	mu        sync.Mutex
+	closed    bool
+}
+
+// Filter selects entries in Query; zero values match everything
+type Filter struct {
+	Since    time.Time         // Entries at or after this time
+	Until    time.Time         // Entries before this time
+	MinLevel *logpy.Level      // Entries at or above this level, e.g. logpy.WarnLevel.Ptr()
+	Message  string            // Substring of the message (case-sensitive)
+	Fields   map[string]string // Field values compared as text, e.g. {"status": "500"}
+	Limit    int               // Maximum number of entries (0 for all)
+	Reverse  bool              // Newest entries first
+}
+
+// Open opens or creates the database at path and stores entries at or above level
+// indexFields are the field keys to index for Query, e.g. "user_id"
+func Open(path string, level logpy.Level, indexFields ...string) (*Store, error) {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+	}
+	// WAL lets Query read while entries are written
+	dsn := "file:" + path + "?_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)&_pragma=busy_timeout(5000)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	store, err := New(db, level, indexFields...)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	store.ownsDB = true
+	return store, nil
+}
+
+// New creates a store in an open SQLite database, creating the tables if needed
+// The database is not closed by Close
+func New(db *sql.DB, level logpy.Level, indexFields ...string) (*Store, error) {
+	if _, err := db.Exec(schema); err != nil {
+		return nil, fmt.Errorf("sqlitelog: create schema: %w", err)
+	}
+	indexed := make(map[string]bool, len(indexFields))
+	for _, key := range indexFields {
+		indexed[key] = true
+	}
+	return &Store{
+		db:      db,
+		level:   level,
+		indexed: indexed,
+		// Durations as strings so stored values compare like the logged ones
+		formatter: &logpy.JSONFormatter{
+			TimestampFormat:  time.RFC3339Nano,
+			DurationEncoding: logpy.DurationString,
+			AddCaller:        true,
+		},
+	}, nil
+}
+
+// Enabled implements the Handler interface
+func (s *Store) Enabled(level logpy.Level) bool {
+	return level.AtLeast(s.level)
+}
+
+// Handle implements the Handler interface
+func (s *Store) Handle(entry logpy.Entry) error {
+	data, err := s.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec("INSERT INTO entries (time, level, message, entry) VALUES (?, ?, ?, ?)",
+		entry.Time.UnixNano(), int(entry.Level), entry.Message, strings.TrimSuffix(string(data), "\n"))
+	if err != nil {
+		return err
+	}
+	if len(s.indexed) > 0 {
+		id, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		for _, fields := range [][]logpy.Field{entry.Fields, entry.ContextFields} {
+			for _, field := range fields {
+				if !s.indexed[field.Key] {
+					continue
+				}
+				if _, err := tx.Exec("INSERT INTO entry_fields (entry_id, key, value) VALUES (?, ?, ?)",
+					id, field.Key, fieldText(field)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// WithFields implements the Handler interface; context fields are stored with each entry
+func (s *Store) WithFields(fields []logpy.Field) logpy.Handler {
+	return s
+}
+
+// Close implements io.Closer, closing the database if the store opened it
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if s.ownsDB {
+		return s.db.Close()
+	}
+	return nil
+}
+
+// Query returns the entries matching filter, oldest first unless filter.Reverse is set
+// Indexed fields are matched by SQLite; other fields are matched while reading the rows
+func (s *Store) Query(filter Filter) ([]logpy.Entry, error) {
+	var where []string
+	var args []interface{}
+	if !filter.Since.IsZero() {
+		where = append(where, "time >= ?")
+		args = append(args, filter.Since.UnixNano())
+	}
+	if !filter.Until.IsZero() {
+		where = append(where, "time < ?")
+		args = append(args, filter.Until.UnixNano())
+	}
+	if filter.MinLevel != nil {
+		// Custom levels are not ordered by value, so match the levels at or above MinLevel
+		var levels []string
+		for _, level := range logpy.Levels() {
+			if level.AtLeast(*filter.MinLevel) {
+				levels = append(levels, "?")
+				args = append(args, int(level))
+			}
+		}
+		where = append(where, "level IN ("+strings.Join(levels, ", ")+")")
+	}
+	if filter.Message != "" {
+		where = append(where, "instr(message, ?) > 0")
+		args = append(args, filter.Message)
+	}
+	unindexed := make(map[string]string)
+	for key, value := range filter.Fields {
+		if !s.indexed[key] {
+			unindexed[key] = value
+			continue
+		}
+		where = append(where, "EXISTS (SELECT 1 FROM entry_fields f WHERE f.entry_id = entries.id AND f.key = ? AND f.value = ?)")
+		args = append(args, key, value)
+	}
+
+	query := "SELECT time, level, entry FROM entries"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	if filter.Reverse {
+		query += " ORDER BY time DESC, id DESC"
+	} else {
+		query += " ORDER BY time, id"
+	}
+	// Rows dropped by unindexed fields would count against a SQL limit
+	if filter.Limit > 0 && len(unindexed) == 0 {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+
+	s.mu.Lock()
+	closed := s.closed
+	s.mu.Unlock()
+	if closed {
+		return nil, ErrClosed
+	}
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []logpy.Entry
+	for rows.Next() && (filter.Limit <= 0 || len(entries) < filter.Limit) {
+		var ns, level int64
+		var line string
+		if err := rows.Scan(&ns, &level, &line); err != nil {
+			return nil, err
+		}
+		entry, err := decode(line)
+		if err != nil {
+			return nil, err
+		}
+		// The columns keep the exact time and custom levels
+		entry.Time = time.Unix(0, ns)
+		entry.Level = logpy.Level(level)
+		if matchFields(entry, unindexed) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, rows.Err()
+}
+
+// Prune deletes the entries before t and returns how many were deleted
+func (s *Store) Prune(before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return 0, ErrClosed
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	ns := before.UnixNano()
+	if _, err := tx.Exec("DELETE FROM entry_fields WHERE entry_id IN (SELECT id FROM entries WHERE time < ?)", ns); err != nil {
+		return 0, err
+	}
+	res, err := tx.Exec("DELETE FROM entries WHERE time < ?", ns)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return n, tx.Commit()
+}
+
+// decode parses a stored JSON line
+func decode(line string) (logpy.Entry, error) {
+	dec := parse.NewDecoder(strings.NewReader(line))
+	dec.SetFormat(parse.FormatJSON)
+	return dec.Decode()
+}
+
+// matchFields reports whether entry has every field in want with the wanted text
+func matchFields(entry logpy.Entry, want map[string]string) bool {
+	for key, value := range want {
+		found := false
+		for _, fields := range [][]logpy.Field{entry.Fields, entry.ContextFields} {
+			for _, field := range fields {
+				if field.Key == key && fieldText(field) == value {
+					found = true
+				}
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldText returns the text a field value is indexed and compared by
+func fieldText(field logpy.Field) string {
+	value := field.Value
+	if fn, ok := value.(func() interface{}); ok && field.Type == logpy.LazyType {
+		value = fn()
+	}
+	switch v := value.(type) {
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case error:
+		return v.Error()
+	default:
+		return fmt.Sprint(v)
+	}
+}
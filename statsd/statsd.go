@@ -0,0 +1,124 @@
+// Package statsd increments StatsD or DogStatsD counters for log entries that
+// match configured rules, bridging logging and metrics without a metrics library.
+//
+// The handler writes no log output; combine it with a regular handler:
+//
+//	counter, err := statsd.NewHandler("127.0.0.1:8125",
+//		statsd.Rule{Metric: "app.errors", Level: logpy.ErrorLevel},
+//		statsd.Rule{Metric: "app.payments.failed", Key: "event", Value: "payment_failed"},
+//	)
+//	logger := logpy.New(logpy.NewMultiHandler(fileHandler, counter))
+package statsd
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/nhatpy/logpy"
+)
+
+// Rule increments Metric by one for every entry at or above Level that has a
+// field Key equal to Value (the field check is skipped when Key is empty)
+type Rule struct {
+	Metric string
+	Level  logpy.Level
+	Key    string
+	Value  string
+	Tags   []string // DogStatsD tags such as "team:payments"
+}
+
+// matches reports whether entry satisfies the rule
+func (r Rule) matches(entry logpy.Entry) bool {
+	if !entry.Level.AtLeast(r.Level) {
+		return false
+	}
+	if r.Key == "" {
+		return true
+	}
+	for _, fields := range [][]logpy.Field{entry.Fields, entry.ContextFields} {
+		for _, field := range fields {
+			if field.Key == r.Key && fmt.Sprint(field.Value) == r.Value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Handler sends a counter increment over UDP for each rule an entry matches
+type Handler struct {
+	conn  net.Conn
+	rules []Rule
+	mu    sync.Mutex
+
+	// Prefix is prepended to every metric name, e.g. "myapp."
+	Prefix string
+	// DogStatsD adds tags (Tags plus each rule's tags and a level tag) in DogStatsD format
+	DogStatsD bool
+	// Tags are added to every metric when DogStatsD is set
+	Tags []string
+}
+
+// NewHandler creates a handler sending to the StatsD server at addr (host:port)
+// With no rules, every entry increments "log.entries.<level>"
+func NewHandler(addr string, rules ...Rule) (*Handler, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to statsd: %w", err)
+	}
+	return &Handler{conn: conn, rules: rules}, nil
+}
+
+// Enabled implements the logpy.Handler interface
+func (h *Handler) Enabled(level logpy.Level) bool {
+	if len(h.rules) == 0 {
+		return true
+	}
+	for _, rule := range h.rules {
+		if level.AtLeast(rule.Level) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle implements the logpy.Handler interface
+func (h *Handler) Handle(entry logpy.Entry) error {
+	if len(h.rules) == 0 {
+		return h.increment("log.entries."+strings.ToLower(entry.Level.String()), entry.Level, nil)
+	}
+	for _, rule := range h.rules {
+		if rule.matches(entry) {
+			if err := h.increment(rule.Metric, entry.Level, rule.Tags); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// increment sends one counter packet
+func (h *Handler) increment(metric string, level logpy.Level, tags []string) error {
+	packet := h.Prefix + metric + ":1|c"
+	if h.DogStatsD {
+		all := append(append([]string{"level:" + strings.ToLower(level.String())}, h.Tags...), tags...)
+		packet += "|#" + strings.Join(all, ",")
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.conn.Write([]byte(packet))
+	return err
+}
+
+// WithFields implements the logpy.Handler interface
+func (h *Handler) WithFields(fields []logpy.Field) logpy.Handler {
+	return h
+}
+
+// Close closes the UDP socket
+func (h *Handler) Close() error {
+	return h.conn.Close()
+}
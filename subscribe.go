@@ -0,0 +1,95 @@
+package logpy
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// subscriberBuffer is the number of entries a slow subscriber can fall behind before entries are dropped
+const subscriberBuffer = 256
+
+// subscriber receives entries at or above level
+type subscriber struct {
+	level Level
+	ch    chan Entry
+}
+
+// subscribers is the set of live subscriptions of a logger and its children
+type subscribers struct {
+	mu    sync.RWMutex
+	subs  map[*subscriber]struct{}
+	count atomic.Int32 // len(subs), read without the lock on every event
+}
+
+// Subscribe returns a channel receiving every entry at or above level logged through
+// this logger or any logger sharing its root, for admin UIs, debug endpoints or tests.
+// Entries are delivered even if the handler filters them out. A subscriber that falls
+// behind misses entries rather than slowing down logging. Call cancel to unsubscribe;
+// it closes the channel. The state shared with the root is created by the logger's
+// constructor; a Logger that was not built by New or NewWithConfig gets a closed channel
+func (l *Logger) Subscribe(level Level) (entries <-chan Entry, cancel func()) {
+	sub := &subscriber{level: level, ch: make(chan Entry, subscriberBuffer)}
+	if l.state == nil {
+		close(sub.ch)
+		return sub.ch, func() {}
+	}
+	s := &l.state.subscribers
+
+	s.mu.Lock()
+	if s.subs == nil {
+		s.subs = make(map[*subscriber]struct{})
+	}
+	s.subs[sub] = struct{}{}
+	s.count.Store(int32(len(s.subs)))
+	s.mu.Unlock()
+
+	var once sync.Once
+	cancel = func() {
+		once.Do(func() {
+			s.mu.Lock()
+			delete(s.subs, sub)
+			s.count.Store(int32(len(s.subs)))
+			close(sub.ch)
+			s.mu.Unlock()
+		})
+	}
+	return sub.ch, cancel
+}
+
+// wants reports whether any subscriber receives entries at level
+func (s *subscribers) wants(level Level) bool {
+	if s.count.Load() == 0 {
+		return false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for sub := range s.subs {
+		if level.AtLeast(sub.level) {
+			return true
+		}
+	}
+	return false
+}
+
+// publish sends entry to every interested subscriber without blocking
+func (s *subscribers) publish(entry Entry) {
+	if s.count.Load() == 0 {
+		return
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.subs) == 0 {
+		return
+	}
+	entry = entry.resolveLazy()
+	for sub := range s.subs {
+		if !entry.Level.AtLeast(sub.level) {
+			continue
+		}
+		select {
+		case sub.ch <- entry:
+		default:
+			// Subscriber is behind; drop rather than block the caller
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package logpy
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// SyncPolicy defines when written entries are flushed to stable storage (fsync)
+type SyncPolicy string
+
+const (
+	SyncNever    SyncPolicy = "never"    // Leave flushing to the operating system (default)
+	SyncAlways   SyncPolicy = "always"   // Sync after every write
+	SyncOnError  SyncPolicy = "error"    // Sync after writing Error level entries
+	SyncInterval SyncPolicy = "interval" // Sync periodically in the background
+)
+
+// syncer is implemented by writers that can flush to stable storage
+type syncer interface {
+	Sync() error
+}
+
+// SetSyncPolicy controls how often the handler's writer is synced to disk
+// interval is only used with SyncInterval (defaulting to one second).
+// It applies to file handlers; writers that cannot sync (e.g. a pipe) are unaffected
+func (h *baseHandler) SetSyncPolicy(policy SyncPolicy, interval time.Duration) {
+	h.stopSync()
+
+	h.mu.Lock()
+	h.syncPolicy = policy
+	h.mu.Unlock()
+
+	if policy != SyncInterval {
+		return
+	}
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	stop := make(chan struct{})
+	h.mu.Lock()
+	h.syncStop = stop
+	h.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				h.mu.Lock()
+				err := h.sync()
+				h.mu.Unlock()
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "error syncing log file: %v\n", err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// syncAfterWrite applies the sync policy after an entry at level was written
+// Callers must hold h.mu
+func (h *baseHandler) syncAfterWrite(level Level) error {
+	switch h.syncPolicy {
+	case SyncAlways:
+		return h.sync()
+	case SyncOnError:
+		if level.AtLeast(ErrorLevel) {
+			return h.sync()
+		}
+	}
+	return nil
+}
+
+// sync flushes the writer if it supports syncing
+func (h *baseHandler) sync() error {
+	if s, ok := h.writer.(syncer); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// stopSync stops the background sync goroutine, if any
+func (h *baseHandler) stopSync() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.syncStop != nil {
+		close(h.syncStop)
+		h.syncStop = nil
+	}
+}
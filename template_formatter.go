@@ -0,0 +1,77 @@
+package logpy
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// TemplateFormatter formats entries with a text/template, to reproduce legacy log
+// layouts exactly. The template receives the Entry, so .Time, .Level, .Message,
+// .Caller, .Fields and .ContextFields are available, plus these functions:
+//
+//	fields .         event and context fields as "key=value ... | key=value"
+//	field . "key"    the value of one field, or "" if it is missing
+//	upper, lower     change the case of a string
+//	pad 5 .Level     left-align a value to a width
+//
+// Example: {{.Time.Format "2006-01-02 15:04:05"}} [{{.Level}}] {{.Message}} {{fields .}}
+type TemplateFormatter struct {
+	tmpl    *template.Template
+	console ConsoleFormatter // Renders field values the same way as console output
+}
+
+// NewTemplateFormatter parses text into a formatter
+// A trailing newline is added to each entry if the template does not end with one
+func NewTemplateFormatter(text string) (*TemplateFormatter, error) {
+	f := &TemplateFormatter{}
+	tmpl, err := template.New("entry").Funcs(template.FuncMap{
+		"fields": f.fields,
+		"field":  f.field,
+		"upper":  func(v interface{}) string { return strings.ToUpper(fmt.Sprint(v)) },
+		"lower":  func(v interface{}) string { return strings.ToLower(fmt.Sprint(v)) },
+		"pad":    func(width int, v interface{}) string { return fmt.Sprintf("%-*v", width, v) },
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse log template: %w", err)
+	}
+	f.tmpl = tmpl
+	return f, nil
+}
+
+// Format implements the Formatter interface for template output
+func (f *TemplateFormatter) Format(entry Entry) ([]byte, error) {
+	entry.Message = sanitizeText(entry.Message)
+	var buf bytes.Buffer
+	if err := f.tmpl.Execute(&buf, entry); err != nil {
+		return nil, err
+	}
+	if buf.Len() == 0 || buf.Bytes()[buf.Len()-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// fields renders all fields logfmt-style, with context fields after a "|"
+func (f *TemplateFormatter) fields(entry Entry) string {
+	var parts []string
+	for _, field := range flattenGroups(entry.Fields) {
+		parts = append(parts, sanitizeText(field.Key)+"="+f.console.fieldValue(field))
+	}
+	if len(entry.ContextFields) > 0 {
+		parts = append(parts, "|")
+		for _, field := range flattenGroups(entry.ContextFields) {
+			parts = append(parts, sanitizeText(field.Key)+"="+f.console.fieldValue(field))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// field renders the value of the field named key
+func (f *TemplateFormatter) field(entry Entry, key string) string {
+	if field, ok := findField(entry, key); ok {
+		return sanitizeText(f.console.rawValue(field))
+	}
+	return ""
+}
@@ -0,0 +1,76 @@
+package logpy
+
+import "fmt"
+
+// Additional ANSI attributes used by the built-in themes
+const (
+	colorDefault = "\033[39m" // Terminal default foreground
+	colorBold    = "\033[1m"
+	colorDim     = "\033[2m"
+)
+
+// Theme names a predefined console color scheme
+type Theme string
+
+const (
+	ThemeDefault      Theme = "default"
+	ThemeSolarized    Theme = "solarized"
+	ThemeMonochrome   Theme = "monochrome"
+	ThemeHighContrast Theme = "high-contrast"
+)
+
+// Color256 returns the ANSI escape code for a foreground color from the 256-color palette
+func Color256(n uint8) string {
+	return fmt.Sprintf("\033[38;5;%dm", n)
+}
+
+// RGB returns the ANSI escape code for a 24-bit (truecolor) foreground color
+func RGB(r, g, b uint8) string {
+	return fmt.Sprintf("\033[38;2;%d;%d;%dm", r, g, b)
+}
+
+// ThemeColorConfig returns the color configuration for a named theme
+// Unknown theme names return the default color configuration
+func ThemeColorConfig(theme Theme) ColorConfig {
+	switch theme {
+	case ThemeSolarized:
+		// Solarized palette by Ethan Schoonover
+		return ColorConfig{
+			Debug:     RGB(0x58, 0x6e, 0x75), // base01
+			Info:      RGB(0x26, 0x8b, 0xd2), // blue
+			Warn:      RGB(0xb5, 0x89, 0x00), // yellow
+			Error:     RGB(0xdc, 0x32, 0x2f), // red
+			Reset:     colorReset,
+			Timestamp: RGB(0x2a, 0xa1, 0x98), // cyan
+			Caller:    RGB(0x65, 0x7b, 0x83), // base00
+			Message:   RGB(0x93, 0xa1, 0xa1), // base1
+			Key:       RGB(0x6c, 0x71, 0xc4), // violet
+			Context:   RGB(0x58, 0x6e, 0x75), // base01
+		}
+	case ThemeMonochrome:
+		// Distinguishes levels with attributes only
+		return ColorConfig{
+			Debug:     colorDim,
+			Info:      colorDefault,
+			Warn:      colorBold,
+			Error:     "\033[1;4m", // Bold + underline
+			Reset:     colorReset,
+			Timestamp: colorDefault,
+			Key:       colorDim,
+		}
+	case ThemeHighContrast:
+		return ColorConfig{
+			Debug:     "\033[97m",      // Bright white
+			Info:      "\033[1;96m",    // Bold bright cyan
+			Warn:      "\033[1;93m",    // Bold bright yellow
+			Error:     "\033[1;97;41m", // Bold white on red
+			Reset:     colorReset,
+			Timestamp: "\033[97m",
+			Caller:    "\033[97m",
+			Message:   colorBold,
+			Key:       "\033[96m", // Bright cyan
+		}
+	default:
+		return DefaultColorConfig()
+	}
+}
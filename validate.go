@@ -0,0 +1,113 @@
+package logpy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Validate reports every invalid setting in the config, joined into one error
+// Empty enum values are valid and select the default
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("logpy: invalid config: "+format, args...))
+		}
+	}
+
+	check(validLevel(c.Level), "Level %d", c.Level)
+	check(c.ConsoleLevel == nil || validLevel(*c.ConsoleLevel), "ConsoleLevel %v", c.ConsoleLevel)
+	check(c.FileLevel == nil || validLevel(*c.FileLevel), "FileLevel %v", c.FileLevel)
+	check(validFormat(c.Format), "Format %q", c.Format)
+	check(validFormat(c.ConsoleFormat), "ConsoleFormat %q", c.ConsoleFormat)
+	check(validFormat(c.FileFormat), "FileFormat %q", c.FileFormat)
+	check(validOutput(c.Output), "Output %q", c.Output)
+	check(validRotation(c.RotationMode), "RotationMode %q", c.RotationMode)
+	check(oneOf(c.ColorMode, "", ColorAuto, ColorAlways, ColorNever), "ColorMode %q", c.ColorMode)
+	check(oneOf(c.DuplicateKeys, "", DuplicateKeepLast, DuplicateKeepFirst, DuplicateSuffixIndex), "DuplicateKeys %q", c.DuplicateKeys)
+	check(validTimeEncoding(c.TimestampEncoding), "TimestampEncoding %q", c.TimestampEncoding)
+	check(validTimeEncoding(c.TimeEncoding), "TimeEncoding %q", c.TimeEncoding)
+	check(oneOf(c.DurationEncoding, "", DurationString, DurationSecs, DurationMillis, DurationNanos), "DurationEncoding %q", c.DurationEncoding)
+	check(oneOf(c.LevelEncoding, "", LevelUpper, LevelLower, LevelNumber, LevelSeverity), "LevelEncoding %q", c.LevelEncoding)
+	check(oneOf(c.SyncPolicy, "", SyncNever, SyncAlways, SyncOnError, SyncInterval), "SyncPolicy %q", c.SyncPolicy)
+
+	check(c.MaxSize >= 0, "MaxSize %d is negative", c.MaxSize)
+	check(c.MaxBackups >= 0, "MaxBackups %d is negative", c.MaxBackups)
+	check(c.MaxAge >= 0, "MaxAge %d is negative", c.MaxAge)
+	check(c.MaxTotalSize >= 0, "MaxTotalSize %d is negative", c.MaxTotalSize)
+	check(c.SyncInterval >= 0, "SyncInterval %s is negative", c.SyncInterval)
+	check(c.ConsoleBufferSize >= 0, "ConsoleBufferSize %d is negative", c.ConsoleBufferSize)
+	check(c.Limits.MaxMessageBytes >= 0 && c.Limits.MaxFieldBytes >= 0 && c.Limits.MaxEntryBytes >= 0 &&
+		c.Limits.MaxFields >= 0 && c.Limits.MaxDepth >= 0, "Limits has a negative value")
+
+	if c.Output == OutputFile && !c.hasSections() {
+		check(c.RotationMode != RotationSize || strings.HasSuffix(c.OutputPath, ".log"),
+			"OutputPath %q must be a .log file for size-based rotation", c.OutputPath)
+	}
+
+	if sec := c.Console; sec != nil {
+		check(sec.Level == nil || validLevel(*sec.Level), "Console.Level %v", sec.Level)
+		check(validFormat(sec.Format), "Console.Format %q", sec.Format)
+		check(oneOf(sec.Color, "", ColorAuto, ColorAlways, ColorNever), "Console.Color %q", sec.Color)
+	}
+	if sec := c.File; sec != nil {
+		check(sec.Level == nil || validLevel(*sec.Level), "File.Level %v", sec.Level)
+		check(validFormat(sec.Format), "File.Format %q", sec.Format)
+		check(validRotation(sec.Rotation), "File.Rotation %q", sec.Rotation)
+		check(sec.MaxSize >= 0 && sec.MaxBackups >= 0 && sec.MaxAge >= 0, "File has a negative limit")
+	}
+	if sec := c.Remote; sec != nil {
+		check(sec.Level == nil || validLevel(*sec.Level), "Remote.Level %v", sec.Level)
+		check(validFormat(sec.Format), "Remote.Format %q", sec.Format)
+		check(sec.Network != "", "Remote.Network is empty")
+		check(sec.Address != "", "Remote.Address is empty")
+		check(sec.BufferSize >= 0, "Remote.BufferSize %d is negative", sec.BufferSize)
+	}
+	return errors.Join(errs...)
+}
+
+// validLevel reports whether level is built in or registered
+func validLevel(level Level) bool {
+	return isKnownLevel(level)
+}
+
+// validFormat reports whether format is empty, built in or registered
+func validFormat(format FormatType) bool {
+	if format == "" || builtinFormat(format) {
+		return true
+	}
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	_, ok := registry.formatters[format]
+	return ok
+}
+
+// validOutput reports whether output is empty, built in or registered
+func validOutput(output OutputType) bool {
+	if oneOf(output, "", OutputStdout, OutputStderr, OutputFile) {
+		return true
+	}
+	_, ok := registeredHandler(output)
+	return ok
+}
+
+// validRotation reports whether mode is empty or a known rotation mode
+func validRotation(mode RotationMode) bool {
+	return oneOf(mode, "", RotationSize, RotationDaily, RotationHourly)
+}
+
+// validTimeEncoding reports whether enc is empty or a known time encoding
+func validTimeEncoding(enc TimestampEncoding) bool {
+	return oneOf(enc, "", TimestampRFC3339, TimestampEpochSecs, TimestampEpochMillis, TimestampEpochNanos, TimestampEpochFloat)
+}
+
+// oneOf reports whether v is one of values
+func oneOf[T comparable](v T, values ...T) bool {
+	for _, value := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
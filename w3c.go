@@ -0,0 +1,155 @@
+package logpy
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultW3CFields is the #Fields directive used when none is configured
+var DefaultW3CFields = []string{
+	"date", "time", "c-ip", "cs-username", "cs-method", "cs-uri-stem", "cs-uri-query",
+	"sc-status", "sc-bytes", "time-taken", "cs(User-Agent)", "cs(Referer)",
+}
+
+// Field keys of HTTP access log entries, written by requestid.AccessLog and read by
+// W3CFormatter and ApacheFormatter
+const (
+	AccessClientIP     = "client_ip"
+	AccessUser         = "user"
+	AccessMethod       = "method"
+	AccessPath         = "path"
+	AccessQuery        = "query"
+	AccessHost         = "host"
+	AccessProto        = "proto"
+	AccessStatus       = "status"
+	AccessBytes        = "bytes"
+	AccessRequestBytes = "request_bytes"
+	AccessDuration     = "duration"
+	AccessUserAgent    = "user_agent"
+	AccessReferer      = "referer"
+)
+
+// w3cFieldKeys maps W3C field identifiers to the entry field keys used for HTTP requests
+// Identifiers not listed here are looked up as field keys directly
+var w3cFieldKeys = map[string]string{
+	"c-ip":           AccessClientIP,
+	"cs-username":    AccessUser,
+	"cs-method":      AccessMethod,
+	"cs-uri-stem":    AccessPath,
+	"cs-uri-query":   AccessQuery,
+	"cs-host":        AccessHost,
+	"cs-version":     AccessProto,
+	"sc-status":      AccessStatus,
+	"sc-bytes":       AccessBytes,
+	"cs-bytes":       AccessRequestBytes,
+	"time-taken":     AccessDuration,
+	"cs(User-Agent)": AccessUserAgent,
+	"cs(Referer)":    AccessReferer,
+}
+
+// W3CFormatter formats access log entries in the W3C extended log format
+// Each entry becomes one space-separated line with a value per configured field;
+// "date" and "time" come from the entry timestamp in UTC, missing values are "-"
+// and spaces are replaced with "+". Use Header for the directives at the top of a file
+type W3CFormatter struct {
+	Fields []string          // W3C field identifiers (defaults to DefaultW3CFields)
+	Keys   map[string]string // Overrides the entry field key for an identifier
+}
+
+// fields returns the configured fields or the default set
+func (f *W3CFormatter) fields() []string {
+	if len(f.Fields) == 0 {
+		return DefaultW3CFields
+	}
+	return f.Fields
+}
+
+// Header returns the #Version, #Software, #Date and #Fields directives
+func (f *W3CFormatter) Header(now time.Time) []byte {
+	return []byte(fmt.Sprintf("#Version: 1.0\n#Software: logpy\n#Date: %s\n#Fields: %s\n",
+		now.UTC().Format("2006-01-02 15:04:05"), strings.Join(f.fields(), " ")))
+}
+
+// Format implements the Formatter interface for W3C output
+func (f *W3CFormatter) Format(entry Entry) ([]byte, error) {
+	fields := f.fields()
+	values := make([]string, len(fields))
+	for i, name := range fields {
+		values[i] = f.value(entry, name)
+	}
+	return []byte(strings.Join(values, " ") + "\n"), nil
+}
+
+// value renders the W3C field name for entry
+func (f *W3CFormatter) value(entry Entry, name string) string {
+	switch name {
+	case "date":
+		return entry.Time.UTC().Format("2006-01-02")
+	case "time":
+		return entry.Time.UTC().Format("15:04:05")
+	}
+
+	key, ok := f.Keys[name]
+	if !ok {
+		if key, ok = w3cFieldKeys[name]; !ok {
+			key = name
+		}
+	}
+	field, ok := findField(entry, key)
+	if !ok {
+		return "-"
+	}
+
+	var s string
+	switch v := field.Value.(type) {
+	case time.Duration:
+		// time-taken is expressed in seconds
+		s = strconv.FormatFloat(v.Seconds(), 'f', 3, 64)
+	default:
+		s = fmt.Sprint(v)
+	}
+	if s == "" {
+		return "-"
+	}
+	return strings.NewReplacer(" ", "+", "\n", "+", "\r", "+", "\t", "+").Replace(s)
+}
+
+// W3CHandler writes W3C extended log format access logs
+// The header directives are written before the first entry
+type W3CHandler struct {
+	*baseHandler
+}
+
+// NewW3CHandler creates a handler writing W3C lines with the given fields (nil uses
+// DefaultW3CFields) to writer. Every entry is written, regardless of level
+func NewW3CHandler(writer io.Writer, fields []string) *W3CHandler {
+	formatter := &W3CFormatter{Fields: fields}
+	return &W3CHandler{
+		baseHandler: &baseHandler{
+			level:     DebugLevel,
+			formatter: formatter,
+			writer:    &headerWriter{w: writer, header: formatter.Header},
+		},
+	}
+}
+
+// headerWriter writes a header before the first write
+type headerWriter struct {
+	w       io.Writer
+	header  func(now time.Time) []byte
+	written bool
+}
+
+// Write implements io.Writer
+func (h *headerWriter) Write(p []byte) (int, error) {
+	if !h.written {
+		if _, err := h.w.Write(h.header(time.Now())); err != nil {
+			return 0, err
+		}
+		h.written = true
+	}
+	return h.w.Write(p)
+}
@@ -0,0 +1,13 @@
+module github.com/nhatpy/logpy/zaplog
+
+go 1.25.0
+
+require (
+	github.com/nhatpy/logpy v0.0.0-20261016204738-105601b6e2d3
+	go.uber.org/zap v1.28.0
+)
+
+require (
+	go.uber.org/multierr v1.10.0 // indirect
+	gopkg.in/natefinch/lumberjack.v2 v2.2.1 // indirect
+)
@@ -0,0 +1,223 @@
+// Package zaplog adapts logpy handlers to zap, so code that logs through zap can
+// keep its call sites while output, rotation and shipping are configured in logpy.
+//
+// Example:
+//
+//	handler := logpy.NewWithConfig(logpy.ProductionConfig()).Handler()
+//	logger := zaplog.New(handler) // or zap.New(zaplog.NewCore(handler), opts...)
+//	logger.Info("order created", zap.String("order_id", id)) // Written by logpy
+package zaplog
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"github.com/nhatpy/logpy"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Core is a zapcore.Core that sends entries to a logpy handler
+// Fields added with With become context fields; the logger name becomes the "logger" field
+// A namespace added with With nests the fields added after it, as in zap
+type Core struct {
+	handler    logpy.Handler
+	fields     []logpy.Field
+	namespaces []namespace // Opened by With, outermost first
+}
+
+// namespace is a namespace opened with With and the fields added to it
+type namespace struct {
+	key    string
+	fields []logpy.Field
+}
+
+// NewCore creates a zap core writing to handler
+func NewCore(handler logpy.Handler) *Core {
+	return &Core{handler: handler}
+}
+
+// New creates a zap logger writing to handler, with caller info
+func New(handler logpy.Handler, opts ...zap.Option) *zap.Logger {
+	return zap.New(NewCore(handler), append([]zap.Option{zap.AddCaller()}, opts...)...)
+}
+
+// Level converts a zap level to a logpy level; DPanic, Panic and Fatal become Error
+func Level(level zapcore.Level) logpy.Level {
+	switch {
+	case level <= zapcore.DebugLevel:
+		return logpy.DebugLevel
+	case level == zapcore.InfoLevel:
+		return logpy.InfoLevel
+	case level == zapcore.WarnLevel:
+		return logpy.WarnLevel
+	default:
+		return logpy.ErrorLevel
+	}
+}
+
+// Enabled implements zapcore.LevelEnabler
+func (c *Core) Enabled(level zapcore.Level) bool {
+	return c.handler.Enabled(Level(level))
+}
+
+// With implements zapcore.Core
+func (c *Core) With(fields []zapcore.Field) zapcore.Core {
+	child := &Core{
+		handler:    c.handler,
+		fields:     append([]logpy.Field(nil), c.fields...),
+		namespaces: make([]namespace, len(c.namespaces)),
+	}
+	for i, ns := range c.namespaces {
+		child.namespaces[i] = namespace{key: ns.key, fields: append([]logpy.Field(nil), ns.fields...)}
+	}
+	for _, f := range fields {
+		switch {
+		case f.Type == zapcore.NamespaceType:
+			child.namespaces = append(child.namespaces, namespace{key: f.Key})
+		case f.Type == zapcore.SkipType:
+		case len(child.namespaces) > 0:
+			ns := &child.namespaces[len(child.namespaces)-1]
+			ns.fields = append(ns.fields, field(f))
+		default:
+			child.fields = append(child.fields, field(f))
+		}
+	}
+	return child
+}
+
+// nest places fields inside the namespaces opened with With
+func (c *Core) nest(fields []logpy.Field) []logpy.Field {
+	for i := len(c.namespaces) - 1; i >= 0; i-- {
+		ns := c.namespaces[i]
+		members := append(append([]logpy.Field(nil), ns.fields...), fields...)
+		fields = []logpy.Field{logpy.Group(ns.key, members...)}
+	}
+	return fields
+}
+
+// Check implements zapcore.Core
+func (c *Core) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write implements zapcore.Core
+// DPanic, Panic and Fatal entries flush the handler, since zap may exit or panic next
+func (c *Core) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	var extra []logpy.Field
+	if entry.LoggerName != "" {
+		extra = append(extra, logpy.String("logger", entry.LoggerName))
+	}
+	logFields := append(extra, c.nest(Fields(fields))...)
+	if entry.Stack != "" {
+		logFields = append(logFields, logpy.String("stack", entry.Stack))
+	}
+
+	logEntry := logpy.Entry{
+		Time:          entry.Time,
+		Level:         Level(entry.Level),
+		Message:       entry.Message,
+		Fields:        logFields,
+		ContextFields: c.fields,
+	}
+	if entry.Caller.Defined {
+		logEntry.Caller = logpy.CallerInfo{
+			File:     filepath.Base(entry.Caller.File),
+			Line:     entry.Caller.Line,
+			Function: entry.Caller.Function,
+		}
+	}
+	err := c.handler.Handle(logEntry)
+	if entry.Level > zapcore.ErrorLevel {
+		err = errors.Join(err, c.Sync())
+	}
+	return err
+}
+
+// Sync implements zapcore.Core by flushing the handler
+func (c *Core) Sync() error {
+	if f, ok := c.handler.(logpy.Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// Fields converts zap fields to logpy fields; a namespace nests the fields after it
+func Fields(fields []zapcore.Field) []logpy.Field {
+	out := make([]logpy.Field, 0, len(fields))
+	for i, f := range fields {
+		if f.Type == zapcore.NamespaceType {
+			return append(out, logpy.Group(f.Key, Fields(fields[i+1:])...))
+		}
+		if f.Type == zapcore.SkipType {
+			continue
+		}
+		out = append(out, field(f))
+	}
+	return out
+}
+
+// field converts a zap field; types without a logpy equivalent are encoded by zap
+func field(f zapcore.Field) logpy.Field {
+	switch f.Type {
+	case zapcore.StringType:
+		return logpy.String(f.Key, f.String)
+	case zapcore.Int64Type, zapcore.Int32Type, zapcore.Int16Type, zapcore.Int8Type:
+		return logpy.Int64(f.Key, f.Integer)
+	case zapcore.Uint64Type, zapcore.Uint32Type, zapcore.Uint16Type, zapcore.Uint8Type, zapcore.UintptrType:
+		return logpy.Uint64(f.Key, uint64(f.Integer))
+	case zapcore.Float64Type:
+		return logpy.Float64(f.Key, math.Float64frombits(uint64(f.Integer)))
+	case zapcore.Float32Type:
+		return logpy.Float32(f.Key, math.Float32frombits(uint32(f.Integer)))
+	case zapcore.BoolType:
+		return logpy.Bool(f.Key, f.Integer == 1)
+	case zapcore.DurationType:
+		return logpy.Duration(f.Key, time.Duration(f.Integer))
+	case zapcore.TimeType:
+		t := time.Unix(0, f.Integer)
+		if loc, ok := f.Interface.(*time.Location); ok {
+			t = t.In(loc)
+		}
+		return logpy.Time(f.Key, t)
+	case zapcore.TimeFullType:
+		if t, ok := f.Interface.(time.Time); ok {
+			return logpy.Time(f.Key, t)
+		}
+	case zapcore.ErrorType:
+		if err, ok := f.Interface.(error); ok {
+			return logpy.Field{Key: f.Key, Type: logpy.ErrorType, Value: text(err, err.Error)}
+		}
+		return logpy.Field{Key: f.Key, Type: logpy.ErrorType}
+	case zapcore.StringerType:
+		if s, ok := f.Interface.(fmt.Stringer); ok {
+			return logpy.String(f.Key, text(s, s.String))
+		}
+	}
+
+	enc := zapcore.NewMapObjectEncoder()
+	f.AddTo(enc)
+	return logpy.Any(f.Key, enc.Fields[f.Key])
+}
+
+// text calls the Error or String method fn of v, recovering from a panic like zap's
+// encoders: a typed nil pointer receiver gives "<nil>"
+func text(v interface{}, fn func() string) (s string) {
+	defer func() {
+		if r := recover(); r != nil {
+			if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+				s = "<nil>"
+				return
+			}
+			s = fmt.Sprintf("PANIC=%v", r)
+		}
+	}()
+	return fn()
+}
@@ -0,0 +1,214 @@
+// Package zerologshim lets code hardwired to zerolog write through logpy handlers:
+// a Writer accepts zerolog's JSON output and re-parses each line into a logpy Entry,
+// so third-party libraries share the application's formatting, rotation and shipping.
+//
+// Example:
+//
+//	handler := logpy.NewWithConfig(logpy.ProductionConfig()).Handler()
+//	zl := zerolog.New(zerologshim.NewWriter(handler)).With().Timestamp().Caller().Logger()
+//	thirdparty.SetLogger(zl)
+package zerologshim
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nhatpy/logpy"
+	"github.com/nhatpy/logpy/parse"
+)
+
+// FieldNames are the keys zerolog writes the entry attributes under
+// They match zerolog.TimestampFieldName, LevelFieldName, ... when those are changed
+type FieldNames struct {
+	Timestamp string // Default "time"
+	Level     string // Default "level"
+	Message   string // Default "message"
+	Caller    string // Default "caller"
+	Error     string // Default "error"
+}
+
+// DefaultFieldNames are zerolog's default keys
+var DefaultFieldNames = FieldNames{
+	Timestamp: "time",
+	Level:     "level",
+	Message:   "message",
+	Caller:    "caller",
+	Error:     "error",
+}
+
+// Writer is an io.Writer for zerolog that sends each JSON line to a logpy handler
+// Lines that are not JSON objects are logged at Info level with the line as message
+type Writer struct {
+	handler logpy.Handler
+	names   FieldNames
+	mu      sync.Mutex
+	partial []byte // Incomplete line from the previous Write
+}
+
+// NewWriter creates a writer that sends zerolog entries to handler
+func NewWriter(handler logpy.Handler) *Writer {
+	return &Writer{handler: handler, names: DefaultFieldNames}
+}
+
+// SetFieldNames sets the keys of the entry attributes; empty names keep the defaults
+func (w *Writer) SetFieldNames(names FieldNames) {
+	if names.Timestamp == "" {
+		names.Timestamp = DefaultFieldNames.Timestamp
+	}
+	if names.Level == "" {
+		names.Level = DefaultFieldNames.Level
+	}
+	if names.Message == "" {
+		names.Message = DefaultFieldNames.Message
+	}
+	if names.Caller == "" {
+		names.Caller = DefaultFieldNames.Caller
+	}
+	if names.Error == "" {
+		names.Error = DefaultFieldNames.Error
+	}
+	w.names = names
+}
+
+// Write implements io.Writer. zerolog writes one entry per call; a line split over
+// several calls is handled once it is complete
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	data := append(w.partial, p...)
+	w.partial = nil
+	var lines [][]byte
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		lines = append(lines, data[:i])
+		data = data[i+1:]
+	}
+	if len(data) > 0 {
+		w.partial = append([]byte(nil), data...)
+	}
+	w.mu.Unlock()
+
+	var errs []error
+	for _, line := range lines {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		entry := w.decode(line)
+		if !w.handler.Enabled(entry.Level) {
+			continue
+		}
+		if err := w.handler.Handle(entry); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return len(p), errors.Join(errs...)
+}
+
+// Flush sends an incomplete last line and flushes the handler
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	partial := w.partial
+	w.partial = nil
+	w.mu.Unlock()
+
+	var err error
+	if len(bytes.TrimSpace(partial)) > 0 {
+		entry := w.decode(partial)
+		if w.handler.Enabled(entry.Level) {
+			err = w.handler.Handle(entry)
+		}
+	}
+	if f, ok := w.handler.(logpy.Flusher); ok {
+		err = errors.Join(err, f.Flush())
+	}
+	return err
+}
+
+// decode converts one zerolog line to an entry, keeping the other keys as fields in order
+func (w *Writer) decode(line []byte) logpy.Entry {
+	entry := logpy.Entry{Time: time.Now(), Level: logpy.InfoLevel}
+
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		entry.Message = string(line)
+		return entry
+	}
+	var fields []logpy.Field
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		key, _ := tok.(string)
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			break
+		}
+
+		switch key {
+		case w.names.Timestamp:
+			if t, err := parse.Time(raw, time.Local); err == nil {
+				entry.Time = t
+				continue
+			}
+		case w.names.Level:
+			var s string
+			if json.Unmarshal(raw, &s) == nil {
+				entry.Level = level(s)
+				continue
+			}
+		case w.names.Message:
+			if json.Unmarshal(raw, &entry.Message) == nil {
+				continue
+			}
+		case w.names.Caller:
+			var s string
+			if json.Unmarshal(raw, &s) == nil {
+				entry.Caller = caller(s)
+				continue
+			}
+		case w.names.Error:
+			var s string
+			if json.Unmarshal(raw, &s) == nil {
+				fields = append(fields, logpy.Field{Key: key, Type: logpy.ErrorType, Value: s})
+				continue
+			}
+		}
+		fields = append(fields, parse.Field(key, raw))
+	}
+	entry.Fields = fields
+	return entry
+}
+
+// level converts a zerolog level name; trace becomes Debug, fatal and panic Error
+func level(s string) logpy.Level {
+	switch strings.ToLower(s) {
+	case "trace":
+		return logpy.DebugLevel
+	case "fatal", "panic":
+		return logpy.ErrorLevel
+	}
+	if level, err := logpy.ParseLevelStrict(s); err == nil {
+		return level
+	}
+	return logpy.InfoLevel
+}
+
+// caller converts "path/to/file.go:42" to caller info
+func caller(s string) logpy.CallerInfo {
+	i := strings.LastIndexByte(s, ':')
+	if i < 0 {
+		return logpy.CallerInfo{File: filepath.Base(s)}
+	}
+	line, _ := strconv.Atoi(s[i+1:])
+	return logpy.CallerInfo{File: filepath.Base(s[:i]), Line: line}
+}